@@ -1,69 +1,226 @@
 package main
 
 import (
+	"context"
+	"fiber-app/src/auth"
 	"fiber-app/src/common"
+	"fiber-app/src/config"
+	"fiber-app/src/health"
+	jobService "fiber-app/src/jobs/services"
+	"fiber-app/src/logging"
+	"fiber-app/src/metrics"
+	"fiber-app/src/migrations"
 	"fiber-app/src/router"
+	"fiber-app/src/scheduler"
+	"fiber-app/src/tracing"
+	"fiber-app/src/utils"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
+var logger = logging.For("main")
+
+const usage = `usage: fiber-app [-config file]                  serve the API
+       fiber-app [-config file] config print [-format yaml|env]
+                                                   print the settings, secrets masked`
+
 func main() {
-	err := run()
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file; CONFIG_FILE by default")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var err error
+	switch args := flag.Args(); {
+	case len(args) == 0:
+		err = run(*configPath)
+	case len(args) >= 2 && args[0] == "config" && args[1] == "print":
+		if err := printConfig(*configPath, args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
 
 	if err != nil {
 		panic(err)
 	}
 }
 
-func run() error {
-    fmt.Println("Initializing environment...")
-    err := common.LoadEnv()
+// printConfig prints the settings the app would run with and where each came from.
+// Invalid settings are printed too, before the error is returned.
+func printConfig(path string, args []string) error {
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	format := flags.String("format", config.FormatYAML, "output format, yaml or env")
+	flags.Parse(args)
+
+	cfg, err := config.Load(path)
+	if cfg != nil {
+		if printErr := config.Print(os.Stdout, cfg, *format); printErr != nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+func run(configPath string) error {
+    logger.Info("loading config")
+    cfg, err := config.Load(configPath)
+    if err != nil {
+        logger.Error("loading config", logging.Err(err))
+        return err
+    }
+
+    if err = logging.Init(cfg.Log); err != nil {
+        logger.Error("initializing logging", logging.Err(err))
+        return err
+    }
+
+    logger.Info("initializing tracing")
+    shutdownTracing, err := tracing.Init(context.Background())
     if err != nil {
-        fmt.Println("Error loading environment:", err)
+        logger.Error("initializing tracing", logging.Err(err))
         return err
     }
 
-    fmt.Println("Initializing database...")
-    err = common.InitDB()
+    defer func() {
+        // Flush the spans of the drain itself, after everything else has stopped.
+        logger.Info("flushing traces")
+        flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancelFlush()
+        if flushErr := shutdownTracing(flushCtx); flushErr != nil {
+            logger.Error("flushing traces", logging.Err(flushErr))
+        }
+    }()
+
+    logger.Info("initializing database")
+    err = common.InitDB(cfg.Mongo)
     if err != nil {
-        fmt.Println("Error initializing database:", err)
+        logger.Error("initializing database", logging.Err(err))
         return err
     }
 
     defer func() {
-        fmt.Println("Closing database...")
+        logger.Info("closing database")
         common.CloseDB()
     }()
 
-    fmt.Println("Creating Fiber app...")
-    app := fiber.New()
+    health.Register("mongodb", health.CheckerFunc(common.PingDB))
+
+    logger.Info("running migrations")
+    migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), 10*time.Minute)
+    err = migrations.Run(migrateCtx)
+    cancelMigrate()
+    if err != nil {
+        logger.Error("running migrations", logging.Err(err))
+        return err
+    }
 
-    fmt.Println("Adding middleware...")
-    app.Use(logger.New())
+    logger.Info("creating Fiber app")
+    app := fiber.New(fiber.Config{
+        // Let large uploads such as book imports be read as a stream instead of buffered.
+        StreamRequestBody: true,
+        // Render every returned error as an application/problem+json body.
+        ErrorHandler: utils.ErrorHandler,
+    })
+
+    logger.Info("adding middleware")
+    app.Use(logging.Middleware())
+    app.Use(metrics.Middleware())
+    app.Use(tracing.Middleware())
     app.Use(recover.New())
     app.Use(cors.New())
+    app.Use(auth.Middleware())
 
-    fmt.Println("Adding routes...")
+    logger.Info("adding routes")
+    router.AddHealthGroup(app)
+    router.AddMetricsGroup(app)
     router.AddBookGroup(app)
+    router.AddJobGroup(app)
+    router.AddAuthorGroup(app)
+    router.AddCopyGroup(app)
+    router.AddLoanGroup(app)
+    router.AddHoldGroup(app)
+    router.AddUserGroup(app)
+    router.AddReviewGroup(app)
+    router.AddListGroup(app)
+    router.AddStatsGroup(app)
+
+    logger.Info("starting job workers")
+    workers := jobService.NewWorkerPool(cfg.Jobs.Workers)
+    if err = workers.Start(); err != nil {
+        logger.Error("starting job workers", logging.Err(err))
+        return err
+    }
+
+    logger.Info("starting scheduled tasks")
+    tasks := scheduler.New()
+    tasks.Start()
 
-    fmt.Println("Starting server...")
-    var port string
-    if port = os.Getenv("PORT"); port == "" {
-        port = "8080"
+    logger.Info("starting server")
+    listenErr := make(chan error, 1)
+    go func() {
+        listenErr <- app.Listen(":" + strconv.Itoa(cfg.Server.Port))
+    }()
+
+    signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stopSignals()
+
+    serving := true
+    select {
+    case err = <-listenErr:
+        serving = false
+        logger.Error("starting server", logging.Err(err))
+    case <-signals.Done():
+        // Restore the default handling so a second signal kills the process at once.
+        stopSignals()
+        health.SetDraining()
+        if delay := cfg.Server.ShutdownDelay; delay > 0 {
+            // Keep serving while readiness fails, so load balancers stop routing here
+            // before the listener closes.
+            logger.Info("waiting for load balancers to deregister", "delay", delay.String())
+            time.Sleep(delay)
+        }
     }
 
-    err = app.Listen(":" + port)
-    if err != nil {
-        fmt.Println("Error starting server:", err)
-        return err
+    // Requests, jobs and tasks share one deadline, so the whole drain fits in the
+    // grace period the orchestrator gives between SIGTERM and SIGKILL.
+    timeout := cfg.Server.ShutdownTimeout
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+
+    if serving {
+        logger.Info("shutting down server, draining in-flight requests", "timeout", timeout.String())
+        if shutdownErr := app.ShutdownWithTimeout(timeout); shutdownErr != nil {
+            logger.Error("shutting down server", logging.Err(shutdownErr))
+        }
+        <-listenErr
+    }
+
+    logger.Info("stopping scheduled tasks")
+    if stopErr := tasks.Stop(ctx); stopErr != nil {
+        logger.Error("stopping scheduled tasks", logging.Err(stopErr))
+    }
+
+    logger.Info("stopping job workers")
+    if stopErr := workers.Stop(ctx); stopErr != nil {
+        logger.Error("stopping job workers", logging.Err(stopErr))
     }
 
-    return nil
+    return err
 }
 
 
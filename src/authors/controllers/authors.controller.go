@@ -0,0 +1,99 @@
+package authorsController
+
+import (
+	"errors"
+
+	"fiber-app/src/authors/dtos"
+	authorService "fiber-app/src/authors/services"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AuthorController struct {
+	authorService *authorService.AuthorService
+}
+
+func NewAuthorController() *AuthorController {
+	return &AuthorController{
+		authorService: authorService.NewAuthorService(),
+	}
+}
+
+func (ac *AuthorController) GetAuthors(c *fiber.Ctx) error {
+	filter := new(dtos.AuthorFilter)
+	if err := utils.ParseQuery(c, filter); err != nil {
+		return err
+	}
+
+	authors, err := ac.authorService.GetAllAuthors(c.UserContext(), filter)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": authors})
+}
+
+func (ac *AuthorController) GetAuthor(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.Validation("id is required")
+	}
+
+	author, err := ac.authorService.GetAuthorByID(c.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return utils.NotFound("author not found")
+		}
+		return err
+	}
+
+	return c.Status(200).JSON(fiber.Map{"data": author})
+}
+
+func (ac *AuthorController) CreateAuthor(c *fiber.Ctx) error {
+	a := new(dtos.CreateDTO)
+	if err := utils.ParseBody(c, a); err != nil {
+		return err
+	}
+
+	result, err := ac.authorService.CreateAuthor(c.UserContext(), a)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(201).JSON(fiber.Map{"result": result})
+}
+
+func (ac *AuthorController) UpdateAuthor(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.Validation("id is required")
+	}
+
+	a := new(dtos.UpdateDTO)
+	if err := utils.ParseBody(c, a); err != nil {
+		return err
+	}
+
+	result, err := ac.authorService.UpdateAuthor(c.UserContext(), id, a)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(200).JSON(fiber.Map{"result": result})
+}
+
+func (ac *AuthorController) DeleteAuthor(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.Validation("id is required")
+	}
+
+	result, err := ac.authorService.DeleteAuthor(c.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(200).JSON(fiber.Map{"result": result})
+}
@@ -0,0 +1,32 @@
+package dtos
+
+import "fiber-app/src/utils"
+
+// CreateDTO represents the structure for creating a new author.
+type CreateDTO struct {
+	Name      string `json:"name" validate:"required,min=2,max=100"`
+	Bio       string `json:"bio,omitempty" validate:"omitempty,max=5000"`
+	BirthYear int    `json:"birthYear,omitempty" validate:"omitempty,year_range"`
+	DeathYear int    `json:"deathYear,omitempty" validate:"omitempty,year_range,gtefield=BirthYear"`
+}
+
+// UpdateDTO represents the structure for updating an existing author.
+type UpdateDTO struct {
+	Name      string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Bio       string `json:"bio,omitempty" validate:"omitempty,max=5000"`
+	BirthYear int    `json:"birthYear,omitempty" validate:"omitempty,year_range"`
+	DeathYear int    `json:"deathYear,omitempty" validate:"omitempty,year_range"`
+}
+
+// AuthorFilter holds the query parameters of the author list endpoint.
+type AuthorFilter struct {
+	Name string `query:"name"`
+}
+
+func (dto *CreateDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
+
+func (dto *UpdateDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
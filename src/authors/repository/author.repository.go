@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuthorRepository interface {
+	GetAllAuthors(ctx context.Context, filter interface{}) ([]models.Author, error)
+	GetAuthorByID(ctx context.Context, id string) (*models.Author, error)
+	CreateAuthor(ctx context.Context, author *models.Author) (*mongo.InsertOneResult, error)
+	UpdateAuthor(ctx context.Context, id string, updateData map[string]interface{}) (*mongo.UpdateResult, error)
+	DeleteAuthor(ctx context.Context, id string) (*mongo.DeleteResult, error)
+	CountAuthorsByIDs(ctx context.Context, ids []primitive.ObjectID) (int64, error)
+	FindAuthorIDsByName(ctx context.Context, name string) ([]primitive.ObjectID, error)
+	FindOrCreateByName(ctx context.Context, name string) (*models.Author, error)
+	CountBooksByAuthor(ctx context.Context, id primitive.ObjectID) (int64, error)
+	FindAuthorsByPrefix(ctx context.Context, prefix string, limit int64) ([]models.Author, error)
+}
+
+type authorRepository struct {
+	commonRepo *common.CommonRepository
+	booksRepo  *common.CommonRepository
+}
+
+// NewAuthorRepository takes the books collection as well so it can check whether
+// an author is still referenced before deleting it.
+func NewAuthorRepository(collection *mongo.Collection, books *mongo.Collection) AuthorRepository {
+	return &authorRepository{
+		commonRepo: common.NewCommonRepository(collection),
+		booksRepo:  common.NewCommonRepository(books),
+	}
+}
+
+func (r *authorRepository) GetAllAuthors(ctx context.Context, filter interface{}) ([]models.Author, error) {
+	var authors []models.Author
+	err := r.commonRepo.FindAll(ctx, filter, &authors, options.Find().SetSort(bson.M{"name": 1}))
+	return authors, err
+}
+
+func (r *authorRepository) GetAuthorByID(ctx context.Context, id string) (*models.Author, error) {
+	objectID, err := r.commonRepo.ConvertID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var author models.Author
+	err = r.commonRepo.FindOne(ctx, bson.M{"_id": objectID}, &author)
+	return &author, err
+}
+
+func (r *authorRepository) CreateAuthor(ctx context.Context, author *models.Author) (*mongo.InsertOneResult, error) {
+	return r.commonRepo.InsertOne(ctx, author)
+}
+
+func (r *authorRepository) UpdateAuthor(ctx context.Context, id string, updateData map[string]interface{}) (*mongo.UpdateResult, error) {
+	objectID, err := r.commonRepo.ConvertID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.commonRepo.UpdateOne(ctx, bson.M{"_id": objectID}, updateData)
+}
+
+func (r *authorRepository) DeleteAuthor(ctx context.Context, id string) (*mongo.DeleteResult, error) {
+	objectID, err := r.commonRepo.ConvertID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.commonRepo.DeleteOne(ctx, bson.M{"_id": objectID})
+}
+
+func (r *authorRepository) CountAuthorsByIDs(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	return r.commonRepo.Count(ctx, bson.M{"_id": bson.M{"$in": ids}})
+}
+
+// FindAuthorIDsByName returns the IDs of authors whose name contains name, ignoring case.
+func (r *authorRepository) FindAuthorIDsByName(ctx context.Context, name string) ([]primitive.ObjectID, error) {
+	values, err := r.commonRepo.Distinct(ctx, "_id", bson.M{"name": primitive.Regex{Pattern: regexp.QuoteMeta(name), Options: "i"}})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// FindOrCreateByName returns the author whose normalized name matches, creating one
+// in the same round trip when there is none.
+func (r *authorRepository) FindOrCreateByName(ctx context.Context, name string) (*models.Author, error) {
+	now := time.Now().UTC()
+	update := bson.M{"$setOnInsert": bson.M{
+		"name":           name,
+		"normalizedName": utils.NormalizePersonName(name),
+		"nameFolded":     utils.FoldText(name),
+		"createdAt":      now,
+		"updatedAt":      now,
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	res, err := r.commonRepo.FindAndModify(ctx, bson.M{"normalizedName": utils.NormalizePersonName(name)}, update, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var author models.Author
+	err = res.Decode(&author)
+	return &author, err
+}
+
+func (r *authorRepository) CountBooksByAuthor(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	return r.booksRepo.Count(ctx, bson.M{"authorIds": id})
+}
+
+// FindAuthorsByPrefix returns up to limit authors whose folded name starts with the
+// already folded prefix. The anchored, case-sensitive regex can use the nameFolded index.
+func (r *authorRepository) FindAuthorsByPrefix(ctx context.Context, prefix string, limit int64) ([]models.Author, error) {
+	var authors []models.Author
+	opts := options.Find().
+		SetProjection(bson.M{"name": 1, "nameFolded": 1}).
+		SetSort(bson.M{"nameFolded": 1}).
+		SetLimit(limit)
+	err := r.commonRepo.FindAll(ctx, bson.M{"nameFolded": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}}, &authors, opts)
+	return authors, err
+}
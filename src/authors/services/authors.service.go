@@ -0,0 +1,192 @@
+package authorService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"fiber-app/src/authors/dtos"
+	"fiber-app/src/authors/repository"
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AuthorService struct {
+	repo repository.AuthorRepository
+}
+
+// NewAuthorService initializes the repository and returns a new AuthorService instance.
+func NewAuthorService() *AuthorService {
+	repo := repository.NewAuthorRepository(common.GetDBCollection("authors"), common.GetDBCollection("books"))
+	return &AuthorService{repo: repo}
+}
+
+func (s *AuthorService) GetAllAuthors(ctx context.Context, filter *dtos.AuthorFilter) ([]models.Author, error) {
+	query := bson.M{}
+	if filter != nil && filter.Name != "" {
+		query["name"] = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Name), Options: "i"}
+	}
+	return s.repo.GetAllAuthors(ctx, query)
+}
+
+func (s *AuthorService) GetAuthorByID(ctx context.Context, id string) (*models.Author, error) {
+	return s.repo.GetAuthorByID(ctx, id)
+}
+
+func (s *AuthorService) CreateAuthor(ctx context.Context, dto *dtos.CreateDTO) (*models.Author, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+
+	now := time.Now().UTC()
+	author := models.Author{
+		Name:           strings.TrimSpace(dto.Name),
+		NormalizedName: utils.NormalizePersonName(dto.Name),
+		NameFolded:     utils.FoldText(dto.Name),
+		Bio:            dto.Bio,
+		BirthYear:      dto.BirthYear,
+		DeathYear:      dto.DeathYear,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	res, err := s.repo.CreateAuthor(ctx, &author)
+	if err != nil {
+		return nil, err
+	}
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		author.ID = oid
+	} else {
+		return nil, errors.New("failed to retrieve inserted ID")
+	}
+
+	return &author, nil
+}
+
+func (s *AuthorService) UpdateAuthor(ctx context.Context, id string, dto *dtos.UpdateDTO) (*models.Author, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+
+	updateData := map[string]interface{}{}
+	if dto.Name != "" {
+		updateData["name"] = strings.TrimSpace(dto.Name)
+		updateData["normalizedName"] = utils.NormalizePersonName(dto.Name)
+		updateData["nameFolded"] = utils.FoldText(dto.Name)
+	}
+	if dto.Bio != "" {
+		updateData["bio"] = dto.Bio
+	}
+	if dto.BirthYear != 0 {
+		updateData["birthYear"] = dto.BirthYear
+	}
+	if dto.DeathYear != 0 {
+		updateData["deathYear"] = dto.DeathYear
+	}
+	updateData["updatedAt"] = time.Now().UTC()
+
+	// A partial update can only be checked against the stored years.
+	if dto.BirthYear != 0 || dto.DeathYear != 0 {
+		current, err := s.repo.GetAuthorByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		birth, death := current.BirthYear, current.DeathYear
+		if dto.BirthYear != 0 {
+			birth = dto.BirthYear
+		}
+		if dto.DeathYear != 0 {
+			death = dto.DeathYear
+		}
+		if birth != 0 && death != 0 && death < birth {
+			return nil, utils.Validation("Field 'deathYear' must not be before 'birthYear'", utils.FieldError{Field: "deathYear", Tag: "gtefield", Param: "birthYear", Message: "must not be before birthYear"})
+		}
+	}
+
+	if _, err := s.repo.UpdateAuthor(ctx, id, updateData); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetAuthorByID(ctx, id)
+}
+
+// DeleteAuthor refuses to delete an author that books still reference.
+func (s *AuthorService) DeleteAuthor(ctx context.Context, id string) (*mongo.DeleteResult, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountBooksByAuthor(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, utils.Conflict("author_in_use", fmt.Sprintf("author is referenced by %d book(s)", count))
+	}
+
+	return s.repo.DeleteAuthor(ctx, id)
+}
+
+// ResolveAuthorIDs turns a mix of author IDs and names into author IDs. IDs must
+// belong to existing authors; names are matched on their normalized form and
+// created when unknown. The result keeps the given order without duplicates.
+func (s *AuthorService) ResolveAuthorIDs(ctx context.Context, ids []string, names []string) ([]primitive.ObjectID, error) {
+	resolved := make([]primitive.ObjectID, 0, len(ids)+len(names))
+	seen := map[primitive.ObjectID]bool{}
+	add := func(id primitive.ObjectID) {
+		if !seen[id] {
+			seen[id] = true
+			resolved = append(resolved, id)
+		}
+	}
+
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, utils.BadRequest("invalid_author", "invalid author id "+id)
+		}
+		add(objectID)
+	}
+	if len(resolved) > 0 {
+		count, err := s.repo.CountAuthorsByIDs(ctx, resolved)
+		if err != nil {
+			return nil, err
+		}
+		if count != int64(len(resolved)) {
+			return nil, utils.BadRequest("invalid_author", "one or more authors do not exist")
+		}
+	}
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		author, err := s.repo.FindOrCreateByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		add(author.ID)
+	}
+
+	return resolved, nil
+}
+
+// FindAuthorIDsByName returns the IDs of authors whose name contains name.
+func (s *AuthorService) FindAuthorIDsByName(ctx context.Context, name string) ([]primitive.ObjectID, error) {
+	return s.repo.FindAuthorIDsByName(ctx, name)
+}
+
+// FindAuthorsByPrefix returns up to limit authors whose name, folded with
+// utils.FoldText, starts with prefix.
+func (s *AuthorService) FindAuthorsByPrefix(ctx context.Context, prefix string, limit int64) ([]models.Author, error) {
+	return s.repo.FindAuthorsByPrefix(ctx, utils.FoldText(prefix), limit)
+}
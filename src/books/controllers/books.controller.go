@@ -1,12 +1,29 @@
 package booksController
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fiber-app/src/auth"
 	"fiber-app/src/books/dtos"
 	bookService "fiber-app/src/books/services"
+	"fiber-app/src/i18n"
+	jobDtos "fiber-app/src/jobs/dtos"
+	"fiber-app/src/logging"
+	"fiber-app/src/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var logger = logging.For("books")
+
 type BookController struct {
 	bookService *bookService.BookService
 }
@@ -17,10 +34,20 @@ func NewBookController() *BookController {
 	}
 }
 
+// RegisterJobHandlers lets this instance's worker pool run book import and export jobs.
+func (bc *BookController) RegisterJobHandlers() {
+	bc.bookService.RegisterJobHandlers()
+}
+
 func (bc *BookController) GetBooks(c *fiber.Ctx) error {
-	books, err := bc.bookService.GetAllBooks(c.Context())
+	filter := new(dtos.BookFilter)
+	if err := utils.ParseQuery(c, filter); err != nil {
+		return err
+	}
+
+	books, err := bc.bookService.GetAllBooks(c.UserContext(), filter)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return c.Status(200).JSON(fiber.Map{"data": books})
 }
@@ -28,12 +55,12 @@ func (bc *BookController) GetBooks(c *fiber.Ctx) error {
 func (bc *BookController) GetBook(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "id is required"})
+		return utils.Validation("id is required")
 	}
 
-	book, err := bc.bookService.GetBookByID(c.Context(), id)
+	book, err := bc.bookService.GetBookByID(c.UserContext(), id)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.Status(200).JSON(fiber.Map{"data": book})
@@ -41,13 +68,13 @@ func (bc *BookController) GetBook(c *fiber.Ctx) error {
 
 func (bc *BookController) CreateBook(c *fiber.Ctx) error {
 	b := new(dtos.CreateDTO) // Use createDTO
-	if err := c.BodyParser(b); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	if err := utils.ParseBody(c, b); err != nil {
+		return err
 	}
 
-	result, err := bc.bookService.CreateBook(c.Context(), b)
+	result, err := bc.bookService.CreateBook(c.UserContext(), b)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to create book", "message": err.Error()})
+		return err
 	}
 
 	return c.Status(201).JSON(fiber.Map{"result": result})
@@ -56,17 +83,17 @@ func (bc *BookController) CreateBook(c *fiber.Ctx) error {
 func (bc *BookController) UpdateBook(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "id is required"})
+		return utils.Validation("id is required")
 	}
 
 	b := new(dtos.UpdateDTO) // Use updateDTO
-	if err := c.BodyParser(b); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	if err := utils.ParseBody(c, b); err != nil {
+		return err
 	}
 
-	result, err := bc.bookService.UpdateBook(c.Context(), id, b)
+	result, err := bc.bookService.UpdateBook(c.UserContext(), id, b)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update book", "message": err.Error()})
+		return err
 	}
 
 	return c.Status(200).JSON(fiber.Map{"result": result})
@@ -75,13 +102,182 @@ func (bc *BookController) UpdateBook(c *fiber.Ctx) error {
 func (bc *BookController) DeleteBook(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "id is required"})
+		return utils.Validation("id is required")
 	}
 
-	result, err := bc.bookService.DeleteBook(c.Context(), id)
+	result, err := bc.bookService.DeleteBook(c.UserContext(), id)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete book", "message": err.Error()})
+		return err
 	}
 
 	return c.Status(200).JSON(fiber.Map{"result": result})
 }
+
+// ImportBooks accepts a CSV or NDJSON file either as the raw request body or as a
+// multipart "file" field and queues it for import. The response carries the job ID
+// to poll at GET /jobs/:id for progress and the per-row error report.
+func (bc *BookController) ImportBooks(c *fiber.Ctx) error {
+	opts := dtos.ImportOptions{}
+	if err := utils.ParseQuery(c, &opts); err != nil {
+		return err
+	}
+
+	var body io.Reader
+	filename := ""
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return utils.Validation("file is required: "+err.Error(), utils.FieldError{Field: "file", Tag: "required", Message: "is required"})
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return utils.BadRequest("invalid_file", "Failed to read file: "+err.Error())
+		}
+		defer file.Close()
+
+		body = file
+		filename = filepath.Base(fileHeader.Filename)
+		if opts.Format == "" {
+			opts.Format = importFormatFromName(fileHeader.Filename)
+		}
+	} else {
+		if stream := c.Context().RequestBodyStream(); stream != nil {
+			body = stream
+		} else {
+			body = bytes.NewReader(c.Body())
+		}
+		if opts.Format == "" {
+			opts.Format = importFormatFromContentType(c.Get(fiber.HeaderContentType))
+		}
+	}
+
+	job, err := bc.bookService.EnqueueImport(c.UserContext(), auth.CurrentUser(c), body, filename, opts)
+	if err != nil {
+		return err
+	}
+
+	c.Location("/jobs/" + job.ID.Hex())
+	return c.Status(202).JSON(fiber.Map{"result": jobDtos.NewJobAccepted(job)})
+}
+
+func importFormatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return dtos.ImportFormatCSV
+	case ".ndjson", ".jsonl":
+		return dtos.ImportFormatNDJSON
+	}
+	return ""
+}
+
+func importFormatFromContentType(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return dtos.ImportFormatCSV
+	case strings.HasPrefix(contentType, "application/x-ndjson"), strings.HasPrefix(contentType, "application/jsonl"):
+		return dtos.ImportFormatNDJSON
+	}
+	return ""
+}
+
+// ExportBooks streams the books matching the list filters as a downloadable file.
+// The body is written after the handler returns, so errors past this point can only
+// be logged and surface to the client as a truncated download.
+func (bc *BookController) ExportBooks(c *fiber.Ctx) error {
+	filter := new(dtos.BookFilter)
+	if err := utils.ParseQuery(c, filter); err != nil {
+		return err
+	}
+
+	format := c.Query("format", dtos.ExportFormatCSV)
+	contentType, extension, err := bookService.ExportContentType(format)
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("books-%s.%s", time.Now().UTC().Format("20060102-150405"), extension)
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	// The body is written after the handler returns, so the export gets a fresh
+	// context that only keeps the request's trace.
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(c.UserContext()))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := bc.bookService.ExportBooks(ctx, filter, format, w, nil); err != nil {
+			logger.ErrorContext(ctx, "exporting books", logging.Err(err))
+			return
+		}
+		if err := w.Flush(); err != nil {
+			logger.ErrorContext(ctx, "flushing book export", logging.Err(err))
+		}
+	})
+
+	return nil
+}
+
+// ExportBooksAsync queues an export of the books matching the list filters. The
+// finished file is downloadable from the link reported by GET /jobs/:id.
+func (bc *BookController) ExportBooksAsync(c *fiber.Ctx) error {
+	filter := new(dtos.BookFilter)
+	if err := utils.ParseQuery(c, filter); err != nil {
+		return err
+	}
+
+	job, err := bc.bookService.EnqueueExport(c.UserContext(), auth.CurrentUser(c), filter, c.Query("format", dtos.ExportFormatCSV))
+	if err != nil {
+		return err
+	}
+
+	c.Location("/jobs/" + job.ID.Hex())
+	return c.Status(202).JSON(fiber.Map{"result": jobDtos.NewJobAccepted(job)})
+}
+
+// BatchBooks applies a list of create, update and delete operations in one bulk write
+// and reports the outcome of each by its index in the request.
+func (bc *BookController) BatchBooks(c *fiber.Ctx) error {
+	req := new(dtos.BatchRequest)
+	if err := utils.ParseBody(c, req); err != nil {
+		return err
+	}
+
+	result, err := bc.bookService.BatchBooks(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	// 207 tells the client to look at the per-item statuses.
+	status := 200
+	trans := i18n.FromRequest(c)
+	for i := range result.Items {
+		item := &result.Items[i]
+		if item.Status != dtos.BatchStatusOK {
+			status = 207
+		}
+		if item.Err == nil {
+			continue
+		}
+		problem := utils.LocalizeError(trans, item.Err)
+		if problem.Status >= fiber.StatusInternalServerError {
+			logger.ErrorContext(c.UserContext(), "batch operation failed", "index", item.Index, "op", item.Op, logging.Err(item.Err))
+		}
+		item.Code, item.Error, item.Fields = problem.Code, problem.Detail, problem.Errors
+	}
+	c.Set(fiber.HeaderContentLanguage, trans.Locale())
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return c.Status(status).JSON(fiber.Map{"result": result})
+}
+
+// GetAuthorBooks lists the books that reference the author in the :id param.
+func (bc *BookController) GetAuthorBooks(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.Validation("id is required")
+	}
+
+	books, err := bc.bookService.GetBooksByAuthor(c.UserContext(), id)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": books})
+}
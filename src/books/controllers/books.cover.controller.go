@@ -0,0 +1,103 @@
+package booksController
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	bookService "fiber-app/src/books/services"
+	"fiber-app/src/storage"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// coverMaxAge is how long clients and proxies may reuse a cover without checking
+// its ETag again.
+const coverMaxAge = 24 * 60 * 60
+
+// coverError reports a missing book or cover blob as a 404 with message.
+func coverError(err error, message string) error {
+	if errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, storage.ErrNotFound) {
+		return utils.NotFound(message)
+	}
+	return err
+}
+
+// UploadCover stores the image in the multipart "file" field as the book's cover
+// and generates its thumbnails.
+func (bc *BookController) UploadCover(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.Validation("file is required: "+err.Error(), utils.FieldError{Field: "file", Tag: "required", Message: "is required"})
+	}
+	if fileHeader.Size > bookService.MaxCoverBytes {
+		return utils.NewError(fiber.StatusRequestEntityTooLarge, "cover_too_large", fmt.Sprintf("cover images can be at most %d bytes", bookService.MaxCoverBytes))
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.BadRequest("invalid_file", "Failed to read file: "+err.Error())
+	}
+	defer file.Close()
+
+	cover, err := bc.bookService.UploadCover(c.UserContext(), c.Params("id"), file)
+	if err != nil {
+		return coverError(err, "book not found")
+	}
+	return c.Status(200).JSON(fiber.Map{"result": cover})
+}
+
+// GetCover serves the book's cover in the size from the ?size= query: original (the
+// default), small, medium or large. Responses carry an ETag and Last-Modified so
+// clients can revalidate cheaply after the max age runs out.
+func (bc *BookController) GetCover(c *fiber.Ctx) error {
+	reader, info, err := bc.bookService.OpenCover(c.UserContext(), c.Params("id"), c.Query("size"))
+	if err != nil {
+		return coverError(err, "cover not found")
+	}
+
+	etag := `"` + info.ETag + `"`
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", coverMaxAge))
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, info.ModTime.UTC().Format(http.TimeFormat))
+	if coverNotModified(c, etag, info.ModTime) {
+		reader.Close()
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	if info.ContentType != "" {
+		c.Set(fiber.HeaderContentType, info.ContentType)
+	}
+	// The stream is closed by fasthttp once it has been sent.
+	return c.Status(200).SendStream(reader, int(info.Size))
+}
+
+// coverNotModified reports whether the client's cached copy is current. As in RFC
+// 9110, If-Modified-Since is only looked at when there is no If-None-Match.
+func coverNotModified(c *fiber.Ctx, etag string, modTime time.Time) bool {
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		for _, tag := range strings.Split(noneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if modifiedSince := c.Get(fiber.HeaderIfModifiedSince); modifiedSince != "" {
+		since, err := http.ParseTime(modifiedSince)
+		return err == nil && !modTime.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// DeleteCover removes the book's cover and its thumbnails.
+func (bc *BookController) DeleteCover(c *fiber.Ctx) error {
+	if err := bc.bookService.DeleteCover(c.UserContext(), c.Params("id")); err != nil {
+		return coverError(err, "book not found")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,33 @@
+package booksController
+
+import (
+	"fiber-app/src/books/dtos"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetBookFacets returns the author, decade, genre and language counts for the books
+// matching the same filters as the list endpoint.
+func (bc *BookController) GetBookFacets(c *fiber.Ctx) error {
+	filter := new(dtos.BookFilter)
+	if err := utils.ParseQuery(c, filter); err != nil {
+		return err
+	}
+
+	facets, err := bc.bookService.GetBookFacets(c.UserContext(), filter)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": facets})
+}
+
+// GetBookStats returns catalog-wide totals, a books-per-year histogram and the top
+// authors.
+func (bc *BookController) GetBookStats(c *fiber.Ctx) error {
+	stats, err := bc.bookService.GetBookStats(c.UserContext())
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": stats})
+}
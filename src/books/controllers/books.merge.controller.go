@@ -0,0 +1,36 @@
+package booksController
+
+import (
+	"fiber-app/src/books/dtos"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetDuplicates lists groups of books that look like duplicates of each other.
+func (bc *BookController) GetDuplicates(c *fiber.Ctx) error {
+	query := new(dtos.DuplicateQuery)
+	if err := utils.ParseQuery(c, query); err != nil {
+		return err
+	}
+
+	groups, err := bc.bookService.FindDuplicates(c.UserContext(), query)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": groups})
+}
+
+// MergeBooks merges the source books into the target book.
+func (bc *BookController) MergeBooks(c *fiber.Ctx) error {
+	dto := new(dtos.MergeDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	result, err := bc.bookService.MergeBooks(c.UserContext(), dto)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": result})
+}
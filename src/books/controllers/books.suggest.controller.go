@@ -0,0 +1,22 @@
+package booksController
+
+import (
+	"fiber-app/src/books/dtos"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetSuggestions completes the ?q= search box text with matching titles and authors.
+func (bc *BookController) GetSuggestions(c *fiber.Ctx) error {
+	query := new(dtos.SuggestQuery)
+	if err := utils.ParseQuery(c, query); err != nil {
+		return err
+	}
+
+	suggestions, err := bc.bookService.Suggest(c.UserContext(), query)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": suggestions})
+}
@@ -0,0 +1,66 @@
+package dtos
+
+import (
+	"encoding/json"
+
+	"fiber-app/src/utils"
+)
+
+// Batch operation kinds.
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+// Batch item statuses.
+const (
+	BatchStatusOK      = "ok"
+	BatchStatusError   = "error"
+	BatchStatusSkipped = "skipped" // Not attempted because an earlier operation failed.
+)
+
+// BatchOperation is one create, update or delete in a batch request. Data holds a
+// CreateDTO for creates and an UpdateDTO for updates.
+type BatchOperation struct {
+	Op   string          `json:"op" validate:"required,oneof=create update delete"`
+	ID   string          `json:"id,omitempty"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// BatchRequest is the body of POST /books/batch.
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,max=1000,dive"`
+	// Ordered stops at the first failing operation instead of attempting the rest.
+	Ordered bool `json:"ordered"`
+	// Transactional applies either every operation or none of them.
+	Transactional bool `json:"transactional"`
+}
+
+// BatchItemResult reports what happened to the operation at Index.
+type BatchItemResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status"`
+	// Err is why the operation failed. The controller renders it into Code, Error and
+	// Fields in the request's language, as the ErrorHandler does.
+	Err    error              `json:"-"`
+	Code   string             `json:"code,omitempty"`
+	Error  string             `json:"error,omitempty"`
+	Fields []utils.FieldError `json:"fields,omitempty"`
+}
+
+// BatchResult summarises a batch request.
+type BatchResult struct {
+	Executed bool              `json:"executed"`
+	Inserted int64             `json:"inserted"`
+	Matched  int64             `json:"matched"`
+	Modified int64             `json:"modified"`
+	Deleted  int64             `json:"deleted"`
+	Items    []BatchItemResult `json:"items"`
+}
+
+func (dto *BatchRequest) Validate() error {
+	return utils.ValidateStruct(dto)
+}
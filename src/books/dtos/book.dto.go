@@ -1,26 +1,40 @@
 package dtos
-import "github.com/go-playground/validator/v10"
+
+import "fiber-app/src/utils"
+
 // CreateDTO represents the structure for creating a new book.
 type CreateDTO struct {
-	Title  string `json:"title" bson:"title" validate:"required,min=3,max=100"`
-	Author string `json:"author" bson:"author" validate:"required,min=3,max=100"`
-	Year   string `json:"year" bson:"year" validate:"required,len=4"` // Year should be exactly 4 digits.
+	Title       string   `json:"title" bson:"title" validate:"required,min=3,max=100"`
+	AuthorIDs   []string `json:"authorIds,omitempty" validate:"required_without=AuthorNames,omitempty,max=20,dive,objectid"`
+	AuthorNames []string `json:"authorNames,omitempty" validate:"required_without=AuthorIDs,omitempty,max=20,dive,min=2,max=100"` // Matched to existing authors or created.
+	ISBN        string   `json:"isbn,omitempty" bson:"isbn,omitempty" validate:"omitempty,isbn"`                                  // ISBN-10 or ISBN-13, hyphens allowed.
+	Year        int      `json:"year" bson:"year" validate:"required,year_range"`                                                 // No later than next year, for announced titles.
+	Publisher   string   `json:"publisher,omitempty" bson:"publisher,omitempty" validate:"omitempty,max=100"`
+	Language    string   `json:"language,omitempty" bson:"language,omitempty" validate:"omitempty,bcp47"`
+	Pages       int      `json:"pages,omitempty" bson:"pages,omitempty" validate:"omitempty,min=1,max=100000"`
+	Genres      []string `json:"genres,omitempty" bson:"genres,omitempty" validate:"omitempty,max=20,dive,min=2,max=40"`
+	Description string   `json:"description,omitempty" bson:"description,omitempty" validate:"omitempty,max=5000"`
 }
 
 // UpdateDTO represents the structure for updating an existing book.
 type UpdateDTO struct {
-	Title  string `json:"title,omitempty" bson:"title,omitempty" validate:"omitempty,min=3,max=100"`
-	Author string `json:"author,omitempty" bson:"author,omitempty" validate:"omitempty,min=3,max=100"`
-	Year   string `json:"year,omitempty" bson:"year,omitempty" validate:"omitempty,len=4"` // Year should be exactly 4 digits.
+	Title       string   `json:"title,omitempty" bson:"title,omitempty" validate:"omitempty,min=3,max=100"`
+	AuthorIDs   []string `json:"authorIds,omitempty" validate:"omitempty,max=20,dive,objectid"`
+	AuthorNames []string `json:"authorNames,omitempty" validate:"omitempty,max=20,dive,min=2,max=100"` // Together with AuthorIDs, replaces the book's authors.
+	ISBN        string   `json:"isbn,omitempty" bson:"isbn,omitempty" validate:"omitempty,isbn"`
+	Year        int      `json:"year,omitempty" bson:"year,omitempty" validate:"omitempty,year_range"`
+	Publisher   string   `json:"publisher,omitempty" bson:"publisher,omitempty" validate:"omitempty,max=100"`
+	Language    string   `json:"language,omitempty" bson:"language,omitempty" validate:"omitempty,bcp47"`
+	Pages       int      `json:"pages,omitempty" bson:"pages,omitempty" validate:"omitempty,min=1,max=100000"`
+	Genres      []string `json:"genres,omitempty" bson:"genres,omitempty" validate:"omitempty,max=20,dive,min=2,max=40"` // A non-nil slice replaces the genres.
+	Description string   `json:"description,omitempty" bson:"description,omitempty" validate:"omitempty,max=5000"`
 }
 
 // Validate method to validate CreateDTO and UpdateDTO.
 func (dto *CreateDTO) Validate() error {
-	validate := validator.New()
-	return validate.Struct(dto)
+	return utils.ValidateStruct(dto)
 }
 
 func (dto *UpdateDTO) Validate() error {
-	validate := validator.New()
-	return validate.Struct(dto)
-}
\ No newline at end of file
+	return utils.ValidateStruct(dto)
+}
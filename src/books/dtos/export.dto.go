@@ -0,0 +1,8 @@
+package dtos
+
+// Supported export formats.
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatXLSX   = "xlsx"
+)
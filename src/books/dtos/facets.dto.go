@@ -0,0 +1,44 @@
+package dtos
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// AuthorCount is how many books reference an author.
+type AuthorCount struct {
+	ID    primitive.ObjectID `json:"id" bson:"_id"`
+	Name  string             `json:"name" bson:"name"`
+	Count int64              `json:"count" bson:"count"`
+}
+
+// ValueCount is how many books have a field value, such as a genre or language.
+type ValueCount struct {
+	Value string `json:"value" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+// YearCount is how many books were published in a year, or in the decade starting
+// at it.
+type YearCount struct {
+	Year  int   `json:"year" bson:"_id"`
+	Count int64 `json:"count" bson:"count"`
+}
+
+// BookFacets are the counts behind the catalog's filter sidebar, for the books
+// matching the current filter. Authors and genres only list the most common values.
+type BookFacets struct {
+	Total     int64         `json:"total" bson:"total"`
+	Authors   []AuthorCount `json:"authors" bson:"authors"`
+	Decades   []YearCount   `json:"decades" bson:"decades"`
+	Genres    []ValueCount  `json:"genres" bson:"genres"`
+	Languages []ValueCount  `json:"languages" bson:"languages"`
+}
+
+// BookStats are catalog-wide totals.
+type BookStats struct {
+	Books           int64         `json:"books" bson:"books"`
+	Authors         int64         `json:"authors" bson:"authors"` // Authors with at least one book.
+	Copies          int64         `json:"copies" bson:"copies"`
+	CopiesAvailable int64         `json:"copiesAvailable" bson:"copiesAvailable"`
+	Ratings         int64         `json:"ratings" bson:"ratings"`
+	BooksPerYear    []YearCount   `json:"booksPerYear" bson:"booksPerYear"`
+	TopAuthors      []AuthorCount `json:"topAuthors" bson:"topAuthors"`
+}
@@ -0,0 +1,17 @@
+package dtos
+
+// BookFilter holds the query parameters shared by the list and export endpoints.
+type BookFilter struct {
+	Title     string `query:"title"`
+	Author    string `query:"author"` // Matches author names.
+	AuthorID  string `query:"authorId"`
+	Year      int    `query:"year"`
+	ISBN      string `query:"isbn"`
+	Publisher string `query:"publisher"`
+	Language  string `query:"language"`
+	Genre     string `query:"genre"`
+	Tag       string `query:"tag"` // Curated tag of a collection.
+	// Sort orders the list by title, year, createdAt or rating; prefix with "-" for
+	// descending, e.g. "-rating" for the best rated first. Exports ignore it.
+	Sort string `query:"sort"`
+}
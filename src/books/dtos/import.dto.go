@@ -0,0 +1,47 @@
+package dtos
+
+// Supported import formats.
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// Supported import modes.
+const (
+	ImportModeInsert = "insert" // Every valid row becomes a new book.
+	ImportModeUpsert = "upsert" // Rows are matched on ISBN and update the columns they fill in, or are inserted.
+)
+
+// ImportOptions controls how an uploaded file is imported.
+type ImportOptions struct {
+	Format    string `query:"format"`
+	Mode      string `query:"mode"`
+	DryRun    bool   `query:"dryRun"`
+	BatchSize int    `query:"batchSize"`
+	// RunKey, when set, makes insert mode idempotent: each row is inserted under
+	// RunKey and its row number, so running the same import again skips the rows an
+	// earlier run already inserted. Import jobs set it to their job ID.
+	RunKey string `query:"-"`
+}
+
+// ImportRowError describes why a single row of an import was rejected.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport summarises the outcome of an import.
+type ImportReport struct {
+	Format    string           `json:"format"`
+	Mode      string           `json:"mode"`
+	DryRun    bool             `json:"dryRun"`
+	Total     int              `json:"total"`
+	Valid     int              `json:"valid"`
+	Inserted  int64            `json:"inserted"`
+	Upserted  int64            `json:"upserted"`
+	Modified  int64            `json:"modified"`
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"errors"`
+	Truncated bool             `json:"truncated"` // True when more errors occurred than are listed.
+}
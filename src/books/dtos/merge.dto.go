@@ -0,0 +1,53 @@
+package dtos
+
+import (
+	"fiber-app/src/models"
+	"fiber-app/src/utils"
+)
+
+// Why books were grouped as duplicates.
+const (
+	DuplicateReasonISBN  = "isbn"
+	DuplicateReasonTitle = "title" // Similar titles and at least one shared author.
+)
+
+// DuplicateQuery holds the query parameters of GET /books/duplicates.
+type DuplicateQuery struct {
+	// Threshold is the lowest title similarity, from 0 to 1, that counts as a
+	// duplicate. Defaults to 0.85.
+	Threshold float64 `query:"threshold" validate:"omitempty,gt=0,lte=1"`
+	Limit     int     `query:"limit" validate:"omitempty,min=1,max=500"` // Defaults to 100 groups.
+}
+
+// DuplicateGroup is a set of books that look like the same title. Similarity is the
+// weakest title match that joined the group; ISBN groups score 1.
+type DuplicateGroup struct {
+	Reason     string        `json:"reason"`
+	Similarity float64       `json:"similarity"`
+	Books      []models.Book `json:"books"`
+}
+
+// MergeDTO is the body of POST /books/merge.
+type MergeDTO struct {
+	TargetID  string   `json:"targetId" validate:"required,objectid"`
+	SourceIDs []string `json:"sourceIds" validate:"required,min=1,max=50,dive,required,objectid"`
+}
+
+// MergeResult reports the merged book and how many references were moved to it.
+type MergeResult struct {
+	Book    *models.Book `json:"book"`
+	Merged  int          `json:"merged"`
+	Copies  int64        `json:"copies"`
+	Loans   int64        `json:"loans"`
+	Holds   int64        `json:"holds"`
+	Reviews int64        `json:"reviews"`
+	Lists   int64        `json:"lists"`
+}
+
+func (dto *DuplicateQuery) Validate() error {
+	return utils.ValidateStruct(dto)
+}
+
+func (dto *MergeDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
@@ -0,0 +1,21 @@
+package dtos
+
+// Suggestion types.
+const (
+	SuggestionTitle  = "title"
+	SuggestionAuthor = "author"
+)
+
+// SuggestQuery holds the query parameters of the suggest endpoint.
+type SuggestQuery struct {
+	Q     string `query:"q"`
+	Limit int    `query:"limit"` // Defaults to 10; at most 20.
+}
+
+// Suggestion is a completion for the search box. ID is the book or author it came
+// from; for a title shared by several books it is the first of them.
+type Suggestion struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	ID   string `json:"id"`
+}
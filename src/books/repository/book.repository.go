@@ -2,20 +2,31 @@ package repository
 
 import (
 	"context"
+	"regexp"
 
+	"fiber-app/src/books/dtos"
 	"fiber-app/src/common"
 	"fiber-app/src/models"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type BookRepository interface {
-	GetAllBooks(ctx context.Context) ([]models.Book, error)
+	GetAllBooks(ctx context.Context, filter interface{}, sort bson.D) ([]models.Book, error)
+	StreamBooks(ctx context.Context, filter interface{}, fn func(*models.Book) error) error
 	GetBookByID(ctx context.Context, id string) (*models.Book, error)
 	CreateBook(ctx context.Context, book *models.Book) (*mongo.InsertOneResult, error)
 	UpdateBook(ctx context.Context, id string, updateData map[string]interface{}) (*mongo.UpdateResult, error)
+	UnsetBookFields(ctx context.Context, id string, fields ...string) (*mongo.UpdateResult, error)
 	DeleteBook(ctx context.Context, id string) (*mongo.DeleteResult, error)
+	FindBooksByTitlePrefix(ctx context.Context, prefix string, limit int64) ([]models.Book, error)
+	FacetBooks(ctx context.Context, filter interface{}, limit int) (*dtos.BookFacets, error)
+	GetBookStats(ctx context.Context, topAuthors int) (*dtos.BookStats, error)
+	BulkWriteBooks(ctx context.Context, operations []mongo.WriteModel, ordered bool) (*mongo.BulkWriteResult, error)
+	WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error)
 }
 
 type bookRepository struct {
@@ -26,21 +37,78 @@ func NewBookRepository(collection *mongo.Collection) BookRepository {
 	return &bookRepository{commonRepo: common.NewCommonRepository(collection)}
 }
 
-func (r *bookRepository) GetAllBooks(ctx context.Context) ([]models.Book, error) {
+// notDeleted matches books that have not been merged away.
+var notDeleted = bson.M{"deletedAt": bson.M{"$exists": false}}
+
+// Live restricts filter to books that have not been merged away. Every read goes
+// through it, and so does every write to an existing book, including those of a
+// batch, so a merged book is not found either way.
+func Live(filter interface{}) bson.M {
+	if filter == nil {
+		return notDeleted
+	}
+	return bson.M{"$and": bson.A{filter, notDeleted}}
+}
+
+// withAuthors builds a pipeline that matches live books and populates their authors.
+func withAuthors(filter interface{}, stages ...bson.D) mongo.Pipeline {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: Live(filter)}}}
+	pipeline = append(pipeline, stages...)
+	return append(pipeline, bson.D{{Key: "$lookup", Value: bson.M{
+		"from":         "authors",
+		"localField":   "authorIds",
+		"foreignField": "_id",
+		"as":           "authors",
+	}}})
+}
+
+// GetAllBooks lists matching books, in sort order when sort is not empty.
+func (r *bookRepository) GetAllBooks(ctx context.Context, filter interface{}, sort bson.D) ([]models.Book, error) {
+	var stages []bson.D
+	if len(sort) > 0 {
+		stages = append(stages, bson.D{{Key: "$sort", Value: sort}})
+	}
+
 	var books []models.Book
-	err := r.commonRepo.FindAll(ctx, bson.M{}, &books)
+	err := r.commonRepo.Aggregate(ctx, withAuthors(filter, stages...), &books)
 	return books, err
 }
 
+// StreamBooks decodes matching books one at a time and hands each to fn, stopping at the first error.
+func (r *bookRepository) StreamBooks(ctx context.Context, filter interface{}, fn func(*models.Book) error) error {
+	pipeline := withAuthors(filter, bson.D{{Key: "$sort", Value: bson.M{"_id": 1}}})
+	cursor, err := r.commonRepo.AggregateCursor(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var book models.Book
+		if err := cursor.Decode(&book); err != nil {
+			return err
+		}
+		if err := fn(&book); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
 func (r *bookRepository) GetBookByID(ctx context.Context, id string) (*models.Book, error) {
 	objectID, err := r.commonRepo.ConvertID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	var book models.Book
-	err = r.commonRepo.FindOne(ctx, bson.M{"_id": objectID}, &book)
-	return &book, err
+	var books []models.Book
+	if err := r.commonRepo.Aggregate(ctx, withAuthors(bson.M{"_id": objectID}), &books); err != nil {
+		return nil, err
+	}
+	if len(books) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &books[0], nil
 }
 
 func (r *bookRepository) CreateBook(ctx context.Context, book *models.Book) (*mongo.InsertOneResult, error) {
@@ -53,7 +121,21 @@ func (r *bookRepository) UpdateBook(ctx context.Context, id string, updateData m
 		return nil, err
 	}
 
-	return r.commonRepo.UpdateOne(ctx, bson.M{"_id": objectID}, updateData)
+	return r.commonRepo.UpdateOne(ctx, Live(bson.M{"_id": objectID}), updateData)
+}
+
+// UnsetBookFields removes the given fields from a book.
+func (r *bookRepository) UnsetBookFields(ctx context.Context, id string, fields ...string) (*mongo.UpdateResult, error) {
+	objectID, err := r.commonRepo.ConvertID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	unset := bson.M{}
+	for _, field := range fields {
+		unset[field] = ""
+	}
+	return r.commonRepo.UpdateOneRaw(ctx, Live(bson.M{"_id": objectID}), bson.M{"$unset": unset})
 }
 
 func (r *bookRepository) DeleteBook(ctx context.Context, id string) (*mongo.DeleteResult, error) {
@@ -62,5 +144,123 @@ func (r *bookRepository) DeleteBook(ctx context.Context, id string) (*mongo.Dele
 		return nil, err
 	}
 
-	return r.commonRepo.DeleteOne(ctx, bson.M{"_id": objectID})
+	return r.commonRepo.DeleteOne(ctx, Live(bson.M{"_id": objectID}))
+}
+
+// FindBooksByTitlePrefix returns up to limit books whose folded title starts with
+// the already folded prefix, without their authors. The anchored, case-sensitive
+// regex can use the titleFolded index.
+func (r *bookRepository) FindBooksByTitlePrefix(ctx context.Context, prefix string, limit int64) ([]models.Book, error) {
+	var books []models.Book
+	opts := options.Find().
+		SetProjection(bson.M{"title": 1, "titleFolded": 1}).
+		SetSort(bson.D{{Key: "titleFolded", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit)
+	err := r.commonRepo.FindAll(ctx, Live(bson.M{"titleFolded": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}}), &books, opts)
+	return books, err
+}
+
+// authorCounts returns the stages that count books per author, most referenced
+// first, and look up the top authors' names.
+func authorCounts(limit int) bson.A {
+	return bson.A{
+		bson.M{"$unwind": "$authorIds"},
+		bson.M{"$group": bson.M{"_id": "$authorIds", "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": limit},
+		bson.M{"$lookup": bson.M{"from": "authors", "localField": "_id", "foreignField": "_id", "as": "author"}},
+		bson.M{"$project": bson.M{"count": 1, "name": bson.M{"$arrayElemAt": bson.A{"$author.name", 0}}}},
+	}
+}
+
+// valueCounts returns the stages that count books per value of field, most common
+// first. Array fields are counted per element.
+func valueCounts(field string, limit int) bson.A {
+	return bson.A{
+		bson.M{"$unwind": "$" + field},
+		bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": limit},
+	}
+}
+
+// FacetBooks counts the matching books by author, decade, genre and language in a
+// single $facet pass. At most limit authors and genres are returned.
+func (r *bookRepository) FacetBooks(ctx context.Context, filter interface{}, limit int) (*dtos.BookFacets, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: Live(filter)}},
+		{{Key: "$facet", Value: bson.M{
+			"total":   bson.A{bson.M{"$count": "count"}},
+			"authors": authorCounts(limit),
+			"decades": bson.A{
+				bson.M{"$match": bson.M{"year": bson.M{"$type": "number"}}},
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$subtract": bson.A{"$year", bson.M{"$mod": bson.A{"$year", 10}}}},
+					"count": bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+			"genres":    valueCounts("genres", limit),
+			"languages": valueCounts("language", limit),
+		}}},
+		{{Key: "$set", Value: bson.M{"total": bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$total.count", 0}}, 0}}}}},
+	}
+
+	var facets []dtos.BookFacets
+	if err := r.commonRepo.Aggregate(ctx, pipeline, &facets); err != nil {
+		return nil, err
+	}
+	return &facets[0], nil
+}
+
+// GetBookStats totals the whole catalog and histograms it by publication year.
+func (r *bookRepository) GetBookStats(ctx context.Context, topAuthors int) (*dtos.BookStats, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: notDeleted}},
+		{{Key: "$facet", Value: bson.M{
+			"totals": bson.A{bson.M{"$group": bson.M{
+				"_id":             nil,
+				"books":           bson.M{"$sum": 1},
+				"copies":          bson.M{"$sum": "$copiesTotal"},
+				"copiesAvailable": bson.M{"$sum": "$copiesAvailable"},
+				"ratings":         bson.M{"$sum": "$ratingCount"},
+			}}},
+			"authors": bson.A{
+				bson.M{"$unwind": "$authorIds"},
+				bson.M{"$group": bson.M{"_id": "$authorIds"}},
+				bson.M{"$count": "count"},
+			},
+			"booksPerYear": bson.A{
+				bson.M{"$match": bson.M{"year": bson.M{"$type": "number"}}},
+				bson.M{"$group": bson.M{"_id": "$year", "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+			"topAuthors": authorCounts(topAuthors),
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"books":           bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$totals.books", 0}}, 0}},
+			"copies":          bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$totals.copies", 0}}, 0}},
+			"copiesAvailable": bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$totals.copiesAvailable", 0}}, 0}},
+			"ratings":         bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$totals.ratings", 0}}, 0}},
+			"authors":         bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$authors.count", 0}}, 0}},
+			"booksPerYear":    1,
+			"topAuthors":      1,
+		}}},
+	}
+
+	var stats []dtos.BookStats
+	if err := r.commonRepo.Aggregate(ctx, pipeline, &stats); err != nil {
+		return nil, err
+	}
+	return &stats[0], nil
+}
+
+// BulkWriteBooks executes the operations in one batch. When ordered is false a failing
+// operation does not stop the ones after it.
+func (r *bookRepository) BulkWriteBooks(ctx context.Context, operations []mongo.WriteModel, ordered bool) (*mongo.BulkWriteResult, error) {
+	return r.commonRepo.BulkWrite(ctx, operations, options.BulkWrite().SetOrdered(ordered))
+}
+
+func (r *bookRepository) WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error) {
+	return r.commonRepo.WithTransaction(ctx, fn)
 }
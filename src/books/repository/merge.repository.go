@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MergeRepository finds duplicate books and moves everything that references a book
+// over to another one.
+type MergeRepository interface {
+	GetDuplicateCandidates(ctx context.Context) ([]models.Book, error)
+	GetLiveBooks(ctx context.Context, ids []primitive.ObjectID) ([]models.Book, error)
+	SoftDeleteBooks(ctx context.Context, ids []primitive.ObjectID, into primitive.ObjectID, now time.Time) error
+	UpdateMergedBook(ctx context.Context, id primitive.ObjectID, set bson.M, inc bson.M) error
+	RepointCopies(ctx context.Context, from []primitive.ObjectID, to primitive.ObjectID) (int64, error)
+	RepointLoans(ctx context.Context, from []primitive.ObjectID, to primitive.ObjectID) (int64, error)
+	RepointHolds(ctx context.Context, from []primitive.ObjectID, to primitive.ObjectID, now time.Time) (int64, error)
+	RepointReviews(ctx context.Context, from []primitive.ObjectID, to primitive.ObjectID) (int64, error)
+	RepointLists(ctx context.Context, from []primitive.ObjectID, to primitive.ObjectID, now time.Time) (int64, error)
+	GetReviewTotals(ctx context.Context, bookID primitive.ObjectID) (count int, sum int, err error)
+	GetBookReferences(ctx context.Context, bookID primitive.ObjectID) ([]string, error)
+	WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error)
+}
+
+type mergeRepository struct {
+	commonRepo  *common.CommonRepository
+	copiesRepo  *common.CommonRepository
+	loansRepo   *common.CommonRepository
+	holdsRepo   *common.CommonRepository
+	reviewsRepo *common.CommonRepository
+	listsRepo   *common.CommonRepository
+}
+
+// NewMergeRepository takes the books collection and every collection that refers
+// to books by ID.
+func NewMergeRepository(books, copies, loans, holds, reviews, lists *mongo.Collection) MergeRepository {
+	return &mergeRepository{
+		commonRepo:  common.NewCommonRepository(books),
+		copiesRepo:  common.NewCommonRepository(copies),
+		loansRepo:   common.NewCommonRepository(loans),
+		holdsRepo:   common.NewCommonRepository(holds),
+		reviewsRepo: common.NewCommonRepository(reviews),
+		listsRepo:   common.NewCommonRepository(lists),
+	}
+}
+
+// GetDuplicateCandidates loads the fields the duplicate finder compares for every
+// live book.
+func (r *mergeRepository) GetDuplicateCandidates(ctx context.Context) ([]models.Book, error) {
+	var books []models.Book
+	opts := options.Find().
+		SetProjection(bson.M{"title": 1, "titleFolded": 1, "authorIds": 1, "isbn": 1, "year": 1, "publisher": 1, "createdAt": 1, "updatedAt": 1}).
+		SetSort(bson.M{"_id": 1})
+	err := r.commonRepo.FindAll(ctx, notDeleted, &books, opts)
+	return books, err
+}
+
+func (r *mergeRepository) GetLiveBooks(ctx context.Context, ids []primitive.ObjectID) ([]models.Book, error) {
+	var books []models.Book
+	err := r.commonRepo.FindAll(ctx, Live(bson.M{"_id": bson.M{"$in": ids}}), &books)
+	return books, err
+}
+
+// SoftDeleteBooks hides the merged books. Their ISBNs move to legacyIsbn so the
+// surviving book can take one over without tripping the unique index, and their
+// copy and rating counts are dropped since those now belong to the surviving book.
+func (r *mergeRepository) SoftDeleteBooks(ctx context.Context, ids []primitive.ObjectID, into primitive.ObjectID, now time.Time) error {
+	_, err := r.commonRepo.UpdateManyRaw(ctx, bson.M{"_id": bson.M{"$in": ids}}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"deletedAt":  now,
+			"mergedInto": into,
+			"updatedAt":  now,
+			"legacyIsbn": bson.M{"$ifNull": bson.A{"$isbn", "$legacyIsbn"}},
+		}}},
+		{{Key: "$unset", Value: bson.A{"isbn", "copiesTotal", "copiesAvailable", "ratingCount", "ratingSum", "ratingAverage"}}},
+	})
+	return err
+}
+
+func (r *mergeRepository) UpdateMergedBook(ctx context.Context, id primitive.ObjectID, set bson.M, inc bson.M) error {
+	update := bson.M{"$set": set}
+	if len(inc) > 0 {
+		update["$inc"] = inc
+	}
+	_, err := r.commonRepo.UpdateOneRaw(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+func (r *mergeRepository) RepointCopies(ctx context.Context, from []primitive.ObjectID, to primitive.ObjectID) (int64, error) {
+	res, err := r.copiesRepo.UpdateMany(ctx, bson.M{"bookId": bson.M{"$in": from}}, bson.M{"bookId": to})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (r *mergeRepository) RepointLoans(ctx context.Context, from []primitive.ObjectID, to primitive.ObjectID) (int64, error) {
+	res, err := r.loansRepo.UpdateMany(ctx, bson.M{"bookId": bson.M{"$in": from}}, bson.M{"bookId": to})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// RepointHolds moves the merged books' open holds into the surviving book's queue,
+// keeping their original creation time and so their place. A reader's waiting hold
+// is cancelled instead when they already hold the surviving book; ready holds
+// always move, since a copy is already set aside for them. Closed holds keep their
+// old book ID as history.
+func (r *mergeRepository) RepointHolds(ctx context.Context, from []primitive.ObjectID, to primitive.ObjectID, now time.Time) (int64, error) {
+	open := bson.M{"$in": bson.A{models.HoldStatusWaiting, models.HoldStatusReady}}
+
+	var existing []models.Hold
+	if err := r.holdsRepo.FindAll(ctx, bson.M{"bookId": to, "status": open}, &existing); err != nil {
+		return 0, err
+	}
+	holders := map[string]bool{}
+	for _, hold := range existing {
+		holders[hold.UserID] = true
+	}
+
+	var holds []models.Hold
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
+	if err := r.holdsRepo.FindAll(ctx, bson.M{"bookId": bson.M{"$in": from}, "status": open}, &holds, opts); err != nil {
+		return 0, err
+	}
+
+	var moved int64
+	for _, hold := range holds {
+		set := bson.M{"bookId": to, "updatedAt": now}
+		if hold.Status == models.HoldStatusWaiting && holders[hold.UserID] {
+			set = bson.M{"status": models.HoldStatusCancelled, "closedAt": now, "updatedAt": now}
+		} else {
+			moved++
+		}
+		if _, err := r.holdsRepo.UpdateOne(ctx, bson.M{"_id": hold.ID}, set); err != nil {
+			return moved, err
+		}
+		holders[hold.UserID] = true
+	}
+	return moved, nil
+}
+
+// RepointReviews moves the merged books' reviews to the surviving book. Readers may
+// only review a book once, so when a reader reviewed more than one of the books the
+// surviving book's review wins, then the most recently updated one; the rest are
+// deleted.
+func (r *mergeRepository) RepointReviews(ctx context.Context, from []primitive.ObjectID, to primitive.ObjectID) (int64, error) {
+	reviewers, err := r.reviewsRepo.Distinct(ctx, "userId", bson.M{"bookId": to})
+	if err != nil {
+		return 0, err
+	}
+	seen := map[string]bool{}
+	for _, userID := range reviewers {
+		if id, ok := userID.(string); ok {
+			seen[id] = true
+		}
+	}
+
+	var reviews []models.Review
+	opts := options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: 1}})
+	if err := r.reviewsRepo.FindAll(ctx, bson.M{"bookId": bson.M{"$in": from}}, &reviews, opts); err != nil {
+		return 0, err
+	}
+
+	var moved int64
+	for _, review := range reviews {
+		if seen[review.UserID] {
+			if _, err := r.reviewsRepo.DeleteOne(ctx, bson.M{"_id": review.ID}); err != nil {
+				return moved, err
+			}
+			continue
+		}
+		if _, err := r.reviewsRepo.UpdateOne(ctx, bson.M{"_id": review.ID}, bson.M{"bookId": to}); err != nil {
+			return moved, err
+		}
+		seen[review.UserID] = true
+		moved++
+	}
+	return moved, nil
+}
+
+// RepointLists replaces the merged books with the surviving book in every list,
+// keeping each list's order and dropping the repeats this creates.
+func (r *mergeRepository) RepointLists(ctx context.Context, from []primitive.ObjectID, to primitive.ObjectID, now time.Time) (int64, error) {
+	merged := map[primitive.ObjectID]bool{}
+	for _, id := range from {
+		merged[id] = true
+	}
+
+	var lists []models.List
+	if err := r.listsRepo.FindAll(ctx, bson.M{"bookIds": bson.M{"$in": from}}, &lists); err != nil {
+		return 0, err
+	}
+
+	for _, list := range lists {
+		bookIDs := make([]primitive.ObjectID, 0, len(list.BookIDs))
+		seen := map[primitive.ObjectID]bool{}
+		for _, id := range list.BookIDs {
+			if merged[id] {
+				id = to
+			}
+			if !seen[id] {
+				seen[id] = true
+				bookIDs = append(bookIDs, id)
+			}
+		}
+		if _, err := r.listsRepo.UpdateOne(ctx, bson.M{"_id": list.ID}, bson.M{"bookIds": bookIDs, "updatedAt": now}); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(lists)), nil
+}
+
+// GetReviewTotals counts a book's reviews and sums their ratings.
+func (r *mergeRepository) GetReviewTotals(ctx context.Context, bookID primitive.ObjectID) (int, int, error) {
+	var totals []struct {
+		Count int `bson:"count"`
+		Sum   int `bson:"sum"`
+	}
+	err := r.reviewsRepo.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"bookId": bookID}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "count": bson.M{"$sum": 1}, "sum": bson.M{"$sum": "$rating"}}}},
+	}, &totals)
+	if err != nil || len(totals) == 0 {
+		return 0, 0, err
+	}
+	return totals[0].Count, totals[0].Sum, nil
+}
+
+// GetBookReferences names what still refers to a book and would be left pointing at
+// nothing if it were deleted: "copies", "active loans", "open holds" and "reviews".
+func (r *mergeRepository) GetBookReferences(ctx context.Context, bookID primitive.ObjectID) ([]string, error) {
+	checks := []struct {
+		name   string
+		repo   *common.CommonRepository
+		filter bson.M
+	}{
+		{"copies", r.copiesRepo, bson.M{"bookId": bookID}},
+		{"active loans", r.loansRepo, bson.M{"bookId": bookID, "status": models.LoanStatusActive}},
+		{"open holds", r.holdsRepo, bson.M{"bookId": bookID, "status": bson.M{"$in": bson.A{models.HoldStatusWaiting, models.HoldStatusReady}}}},
+		{"reviews", r.reviewsRepo, bson.M{"bookId": bookID}},
+	}
+
+	var refs []string
+	for _, check := range checks {
+		exists, err := check.repo.Exists(ctx, check.filter)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			refs = append(refs, check.name)
+		}
+	}
+	return refs, nil
+}
+
+func (r *mergeRepository) WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error) {
+	return r.commonRepo.WithTransaction(ctx, fn)
+}
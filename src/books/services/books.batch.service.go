@@ -0,0 +1,337 @@
+package bookService
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"fiber-app/src/books/dtos"
+	"fiber-app/src/books/repository"
+	"fiber-app/src/logging"
+	"fiber-app/src/metrics"
+	"fiber-app/src/tracing"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BatchBooks validates every operation and runs the valid ones in order: each run of
+// creates and updates as one bulk write, and each delete on its own, as DeleteBook
+// does. Updates and deletes of books that are missing or merged away fail as not
+// found. The bulk result only carries totals, so an update whose book is merged away
+// between that check and the write is still reported as "ok"; the Matched count
+// shows the difference.
+func (s *BookService) BatchBooks(ctx context.Context, req *dtos.BatchRequest) (_ *dtos.BatchResult, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.BatchBooks")
+	defer func() { tracing.End(span, err) }()
+
+	if err := req.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+
+	live, err := s.liveBatchTargets(ctx, req.Operations)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dtos.BatchResult{Items: make([]dtos.BatchItemResult, len(req.Operations))}
+	writes := make([]batchWrite, 0, len(req.Operations))
+	invalid := false
+
+	for i, op := range req.Operations {
+		item := &result.Items[i]
+		item.Index = i
+		item.Op = op.Op
+
+		if invalid && (req.Ordered || req.Transactional) {
+			item.Status = dtos.BatchStatusSkipped
+			continue
+		}
+
+		write, err := s.batchWrite(ctx, op, live)
+		item.ID = write.id
+		if err != nil {
+			item.Status = dtos.BatchStatusError
+			item.Err = err
+			invalid = true
+			continue
+		}
+
+		item.Status = dtos.BatchStatusOK
+		write.index = i
+		writes = append(writes, write)
+	}
+
+	if invalid && req.Transactional {
+		markBatchSkipped(result, writes)
+		return result, nil
+	}
+	if len(writes) == 0 {
+		return result, nil
+	}
+
+	var out *batchOutcome
+	if req.Transactional {
+		_, err = s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			var txErr error
+			out, txErr = s.applyBatch(sessCtx, writes, true, true)
+			if txErr == nil && len(out.failed) > 0 {
+				txErr = errBatchFailed
+			}
+			return nil, txErr
+		})
+		if errors.Is(err, errBatchFailed) {
+			// The transaction was aborted, so nothing else was applied either.
+			markBatchFailed(result, out)
+			markBatchSkipped(result, writes)
+			return result, nil
+		}
+	} else {
+		out, err = s.applyBatch(ctx, writes, req.Ordered, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	firstFailed := markBatchFailed(result, out)
+	if req.Ordered {
+		for i, write := range writes {
+			if write.index > firstFailed {
+				markBatchSkipped(result, writes[i:])
+				break
+			}
+		}
+	}
+
+	result.Executed = true
+	suggestions.Purge()
+	result.Inserted = out.inserted
+	result.Matched = out.matched
+	result.Modified = out.modified
+	result.Deleted = int64(len(out.deleted))
+	metrics.BooksCreated.Add(float64(out.inserted))
+	metrics.BooksDeleted.Add(float64(len(out.deleted)))
+	// The books are gone either way, so a leftover cover is only logged.
+	for _, bookID := range out.deleted {
+		if err := s.deleteCoverBlobs(ctx, bookID); err != nil {
+			logger.WarnContext(ctx, "deleting book cover", "book_id", bookID.Hex(), logging.Err(err))
+		}
+	}
+	return result, nil
+}
+
+// errBatchFailed aborts the transaction of a transactional batch one of whose
+// operations failed.
+var errBatchFailed = errors.New("batch operation failed")
+
+// batchWrite is one valid operation of a batch: the write model of a create or an
+// update, or the book a delete removes.
+type batchWrite struct {
+	index  int // Position of the operation in the request.
+	id     string
+	model  mongo.WriteModel // Nil for deletes.
+	delete primitive.ObjectID
+}
+
+// batchOutcome is what applying a batch's writes did. failed holds the error of each
+// operation that failed, by its position in the request.
+type batchOutcome struct {
+	inserted int64
+	matched  int64
+	modified int64
+	deleted  []primitive.ObjectID
+	failed   map[int]error
+}
+
+// applyBatch runs the writes in order, sending each run of creates and updates as one
+// bulk write. Deletes run one by one, each checking first that nothing refers to its
+// book and in the same transaction as that check; outside a transaction each delete
+// gets one of its own. When ordered, it stops at the first write that fails.
+func (s *BookService) applyBatch(ctx context.Context, writes []batchWrite, ordered, inTransaction bool) (*batchOutcome, error) {
+	out := &batchOutcome{failed: map[int]error{}}
+	start := 0
+	flush := func(end int) error {
+		if start == end {
+			return nil
+		}
+		operations := make([]mongo.WriteModel, 0, end-start)
+		for _, write := range writes[start:end] {
+			operations = append(operations, write.model)
+		}
+		res, err := s.repo.BulkWriteBooks(ctx, operations, ordered)
+		if res != nil {
+			out.inserted += res.InsertedCount
+			out.matched += res.MatchedCount
+			out.modified += res.ModifiedCount
+		}
+		if err != nil {
+			var bulkErr mongo.BulkWriteException
+			if !errors.As(err, &bulkErr) || len(bulkErr.WriteErrors) == 0 {
+				return err
+			}
+			for _, writeErr := range bulkErr.WriteErrors {
+				if writeErr.Index >= 0 && writeErr.Index < len(operations) {
+					out.failed[writes[start+writeErr.Index].index] = mongo.WriteException{WriteErrors: mongo.WriteErrors{writeErr.WriteError}}
+				}
+			}
+		}
+		start = end
+		return nil
+	}
+
+	for i, write := range writes {
+		if write.model != nil {
+			continue
+		}
+		if err := flush(i); err != nil {
+			return nil, err
+		}
+		start = i + 1
+		if ordered && len(out.failed) > 0 {
+			return out, nil
+		}
+
+		remove := func(ctx context.Context) (interface{}, error) {
+			return s.deleteUnreferencedBook(ctx, write.id)
+		}
+		var err error
+		if inTransaction {
+			_, err = remove(ctx)
+		} else {
+			_, err = s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+				return remove(sessCtx)
+			})
+		}
+		var e *utils.Error
+		switch {
+		case err == nil:
+			out.deleted = append(out.deleted, write.delete)
+		case errors.Is(err, mongo.ErrNoDocuments), errors.As(err, &e):
+			out.failed[write.index] = err
+		default:
+			return nil, err
+		}
+		if ordered && len(out.failed) > 0 {
+			return out, nil
+		}
+	}
+	if err := flush(len(writes)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// markBatchFailed reports the operations that failed while the batch was applied
+// and returns the position of the first one, or the number of items when none did.
+func markBatchFailed(result *dtos.BatchResult, out *batchOutcome) int {
+	first := len(result.Items)
+	for i, err := range out.failed {
+		result.Items[i].Status = dtos.BatchStatusError
+		result.Items[i].Err = err
+		first = min(first, i)
+	}
+	return first
+}
+
+// liveBatchTargets returns which of the books the batch updates or deletes exist
+// and have not been merged away.
+func (s *BookService) liveBatchTargets(ctx context.Context, ops []dtos.BatchOperation) (map[primitive.ObjectID]bool, error) {
+	var ids []primitive.ObjectID
+	for _, op := range ops {
+		if op.Op == dtos.BatchOpCreate {
+			continue
+		}
+		if id, err := primitive.ObjectIDFromHex(op.ID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	live := make(map[primitive.ObjectID]bool, len(ids))
+	if len(ids) == 0 {
+		return live, nil
+	}
+	books, err := s.merges.GetLiveBooks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, book := range books {
+		live[book.ID] = true
+	}
+	return live, nil
+}
+
+// markBatchSkipped marks the items of the given writes as skipped unless they
+// already failed.
+func markBatchSkipped(result *dtos.BatchResult, writes []batchWrite) {
+	for _, write := range writes {
+		if result.Items[write.index].Status == dtos.BatchStatusOK {
+			result.Items[write.index].Status = dtos.BatchStatusSkipped
+		}
+	}
+}
+
+// batchWrite validates one operation and converts it to a write. Creates get their
+// ID up front so it can be reported back. Author names are resolved here, so an
+// unknown name creates its author even if the batch is later rolled back. Updates
+// and deletes of books that are not in live fail; whether anything still refers to
+// a deleted book is only checked when the delete runs. Failures are *utils.Error
+// values, except those of resolving authors, which the controller reports as
+// internal errors.
+func (s *BookService) batchWrite(ctx context.Context, op dtos.BatchOperation, live map[primitive.ObjectID]bool) (batchWrite, error) {
+	write := batchWrite{id: op.ID}
+	var objectID primitive.ObjectID
+	if op.Op != dtos.BatchOpCreate {
+		var err error
+		if objectID, err = primitive.ObjectIDFromHex(op.ID); err != nil {
+			return write, utils.BadRequest("invalid_id", "invalid id")
+		}
+		if !live[objectID] {
+			return write, utils.NotFound("resource not found")
+		}
+	}
+
+	switch op.Op {
+	case dtos.BatchOpCreate:
+		dto := new(dtos.CreateDTO)
+		if err := json.Unmarshal(op.Data, dto); err != nil {
+			return write, utils.BadRequest("invalid_body", "invalid data: "+err.Error())
+		}
+		if err := dto.Validate(); err != nil {
+			return write, utils.ValidationFailed(err)
+		}
+		authorIDs, err := s.authors.ResolveAuthorIDs(ctx, dto.AuthorIDs, dto.AuthorNames)
+		if err != nil {
+			return write, err
+		}
+		book := newBookFromDTO(dto, authorIDs)
+		book.ID = primitive.NewObjectID()
+		write.id = book.ID.Hex()
+		write.model = mongo.NewInsertOneModel().SetDocument(book)
+		return write, nil
+
+	case dtos.BatchOpUpdate:
+		dto := new(dtos.UpdateDTO)
+		if err := json.Unmarshal(op.Data, dto); err != nil {
+			return write, utils.BadRequest("invalid_body", "invalid data: "+err.Error())
+		}
+		if err := dto.Validate(); err != nil {
+			return write, utils.ValidationFailed(err)
+		}
+		updateData, err := s.bookUpdateData(ctx, dto)
+		if err != nil {
+			return write, err
+		}
+		if len(updateData) == 0 {
+			return write, utils.Validation("no fields to update")
+		}
+		write.model = mongo.NewUpdateOneModel().SetFilter(repository.Live(bson.M{"_id": objectID})).SetUpdate(bson.M{"$set": updateData})
+		return write, nil
+
+	case dtos.BatchOpDelete:
+		write.delete = objectID
+		return write, nil
+	}
+
+	return write, utils.BadRequest("invalid_op", "unknown op "+op.Op)
+}
@@ -0,0 +1,221 @@
+package bookService
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"time"
+
+	"fiber-app/src/models"
+	"fiber-app/src/storage"
+	"fiber-app/src/tracing"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Registers the WebP decoder.
+)
+
+const (
+	// MaxCoverBytes is the largest cover image that can be uploaded.
+	MaxCoverBytes = 5 << 20
+	// maxCoverPixels guards against small files that decode to huge images.
+	maxCoverPixels = 40_000_000
+
+	CoverOriginal = "original"
+	coverBucket   = "covers"
+	coverQuality  = 85
+)
+
+// coverSizes are the thumbnail sizes with their width in pixels. Thumbnails are
+// never wider than the original.
+var coverSizes = []struct {
+	Name  string
+	Width int
+}{
+	{"small", 150},
+	{"medium", 300},
+	{"large", 600},
+}
+
+// coverContentTypes are the image types accepted for upload, as sniffed from the
+// file's content rather than taken from the client.
+var coverContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+func coverKey(bookID primitive.ObjectID, size string) string {
+	return "books/" + bookID.Hex() + "/" + size
+}
+
+// UploadCover validates the image read from r, stores it with a thumbnail for each
+// size and records the cover on the book. An existing cover is replaced.
+func (s *BookService) UploadCover(ctx context.Context, id string, r io.Reader) (_ *models.BookCover, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.UploadCover")
+	defer func() { tracing.End(span, err) }()
+
+	bookID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, utils.Validation("invalid book id")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, MaxCoverBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxCoverBytes {
+		return nil, utils.NewError(http.StatusRequestEntityTooLarge, "cover_too_large", fmt.Sprintf("cover images can be at most %d bytes", MaxCoverBytes))
+	}
+	contentType := http.DetectContentType(data)
+	if !coverContentTypes[contentType] {
+		return nil, utils.NewError(http.StatusUnsupportedMediaType, "unsupported_media_type", "cover must be a JPEG, PNG or WebP image")
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, utils.NewError(http.StatusUnprocessableEntity, "invalid_image", "cover image could not be read: "+err.Error())
+	}
+	if config.Width*config.Height > maxCoverPixels {
+		return nil, utils.NewError(http.StatusUnprocessableEntity, "invalid_image", "cover image dimensions are too large")
+	}
+
+	if _, err := s.repo.GetBookByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, utils.NewError(http.StatusUnprocessableEntity, "invalid_image", "cover image could not be read: "+err.Error())
+	}
+
+	if _, err := s.covers.Put(ctx, coverKey(bookID, CoverOriginal), contentType, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	sizes := []string{CoverOriginal}
+	for _, size := range coverSizes {
+		thumbnail, thumbnailType, err := encodeThumbnail(img, size.Width, contentType)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.covers.Put(ctx, coverKey(bookID, size.Name), thumbnailType, thumbnail); err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, size.Name)
+	}
+
+	cover := &models.BookCover{
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+		Sizes:       sizes,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if _, err := s.repo.UpdateBook(ctx, id, map[string]interface{}{"cover": cover, "updatedAt": cover.UpdatedAt}); err != nil {
+		return nil, err
+	}
+	return cover, nil
+}
+
+// encodeThumbnail scales img down to width, keeping its aspect ratio. PNGs stay PNGs
+// so transparency survives; everything else is flattened onto white as a JPEG.
+func encodeThumbnail(img image.Image, width int, contentType string) (io.Reader, string, error) {
+	bounds := img.Bounds()
+	if width > bounds.Dx() {
+		width = bounds.Dx()
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	buf := new(bytes.Buffer)
+	if contentType == "image/png" {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+		if err := png.Encode(buf, dst); err != nil {
+			return nil, "", err
+		}
+		return buf, "image/png", nil
+	}
+
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	if err := jpeg.Encode(buf, dst, &jpeg.Options{Quality: coverQuality}); err != nil {
+		return nil, "", err
+	}
+	return buf, "image/jpeg", nil
+}
+
+// OpenCover opens a book's cover in the given size; an empty size means the
+// original. It returns storage.ErrNotFound when the book has no cover.
+func (s *BookService) OpenCover(ctx context.Context, id, size string) (_ io.ReadCloser, _ *storage.BlobInfo, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.OpenCover")
+	defer func() { tracing.End(span, err) }()
+
+	bookID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil, utils.Validation("invalid book id")
+	}
+	if size == "" {
+		size = CoverOriginal
+	}
+	if !validCoverSize(size) {
+		return nil, nil, utils.BadRequest("invalid_size", "size must be one of original, small, medium or large")
+	}
+	return s.covers.Open(ctx, coverKey(bookID, size))
+}
+
+// DeleteCover removes a book's cover image and thumbnails.
+func (s *BookService) DeleteCover(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "BookService.DeleteCover")
+	defer func() { tracing.End(span, err) }()
+
+	bookID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return utils.Validation("invalid book id")
+	}
+
+	res, err := s.repo.UnsetBookFields(ctx, id, "cover")
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return s.deleteCoverBlobs(ctx, bookID)
+}
+
+func (s *BookService) deleteCoverBlobs(ctx context.Context, bookID primitive.ObjectID) error {
+	var errs []error
+	if err := s.covers.Delete(ctx, coverKey(bookID, CoverOriginal)); err != nil {
+		errs = append(errs, err)
+	}
+	for _, size := range coverSizes {
+		if err := s.covers.Delete(ctx, coverKey(bookID, size.Name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validCoverSize(size string) bool {
+	if size == CoverOriginal {
+		return true
+	}
+	for _, s := range coverSizes {
+		if s.Name == size {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package bookService
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-app/src/books/dtos"
+	"fiber-app/src/models"
+	"fiber-app/src/tracing"
+	"fiber-app/src/utils"
+)
+
+// exportColumns is the column order used by the tabular export formats.
+var exportColumns = []string{"id", "title", "authors", "authorIds", "isbn", "year", "publisher", "language", "pages", "genres", "description", "createdAt", "updatedAt"}
+
+// bookExporter encodes books one at a time in a specific export format.
+type bookExporter interface {
+	WriteBook(book *models.Book) error
+	Close() error
+}
+
+// ExportContentType validates the export format and returns the content type and
+// file extension to advertise for it.
+func ExportContentType(format string) (string, string, error) {
+	switch strings.ToLower(format) {
+	case "", dtos.ExportFormatCSV:
+		return "text/csv; charset=utf-8", "csv", nil
+	case dtos.ExportFormatNDJSON:
+		return "application/x-ndjson", "ndjson", nil
+	case dtos.ExportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx", nil
+	}
+	return "", "", utils.BadRequest("invalid_format", "format must be one of 'csv', 'ndjson' or 'xlsx'")
+}
+
+// ExportBooks streams every book matching the filter to w in the requested format.
+// Books are read through a cursor so memory use does not grow with the collection.
+// progress, if set, is called with the number of books written so far.
+func (s *BookService) ExportBooks(ctx context.Context, filter *dtos.BookFilter, format string, w io.Writer, progress func(int64)) (err error) {
+	ctx, span := tracing.Start(ctx, "BookService.ExportBooks")
+	defer func() { tracing.End(span, err) }()
+
+	if _, _, err := ExportContentType(format); err != nil {
+		return err
+	}
+
+	exporter, err := newBookExporter(w, strings.ToLower(format))
+	if err != nil {
+		return err
+	}
+
+	query, err := s.buildBookFilter(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	var written int64
+	err = s.repo.StreamBooks(ctx, query, func(book *models.Book) error {
+		if err := exporter.WriteBook(book); err != nil {
+			return err
+		}
+		written++
+		if progress != nil {
+			progress(written)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return exporter.Close()
+}
+
+func newBookExporter(w io.Writer, format string) (bookExporter, error) {
+	switch format {
+	case dtos.ExportFormatNDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonExporter{w: bw, enc: json.NewEncoder(bw)}, nil
+	case dtos.ExportFormatXLSX:
+		xw, err := utils.NewXLSXWriter(w, "Books")
+		if err != nil {
+			return nil, err
+		}
+		if err := xw.WriteRow(exportColumns); err != nil {
+			return nil, err
+		}
+		return &xlsxExporter{w: xw}, nil
+	default:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportColumns); err != nil {
+			return nil, err
+		}
+		return &csvExporter{w: cw}, nil
+	}
+}
+
+func exportRecord(book *models.Book) []string {
+	names := make([]string, len(book.Authors))
+	for i, a := range book.Authors {
+		names[i] = a.Name
+	}
+	ids := make([]string, len(book.AuthorIDs))
+	for i, id := range book.AuthorIDs {
+		ids[i] = id.Hex()
+	}
+
+	return []string{
+		book.ID.Hex(),
+		book.Title,
+		strings.Join(names, ";"),
+		strings.Join(ids, ";"),
+		book.ISBN,
+		formatOptionalInt(book.Year),
+		book.Publisher,
+		book.Language,
+		formatOptionalInt(book.Pages),
+		strings.Join(book.Genres, ";"),
+		book.Description,
+		book.CreatedAt.Format(time.RFC3339),
+		book.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func formatOptionalInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvExporter) WriteBook(book *models.Book) error {
+	return e.w.Write(exportRecord(book))
+}
+
+func (e *csvExporter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonExporter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonExporter) WriteBook(book *models.Book) error {
+	return e.enc.Encode(book)
+}
+
+func (e *ndjsonExporter) Close() error {
+	return e.w.Flush()
+}
+
+type xlsxExporter struct {
+	w *utils.XLSXWriter
+}
+
+func (e *xlsxExporter) WriteBook(book *models.Book) error {
+	return e.w.WriteRow(exportRecord(book))
+}
+
+func (e *xlsxExporter) Close() error {
+	return e.w.Close()
+}
@@ -0,0 +1,37 @@
+package bookService
+
+import (
+	"context"
+
+	"fiber-app/src/books/dtos"
+	"fiber-app/src/tracing"
+)
+
+const (
+	// facetLimit caps the authors and genres listed in the filter facets.
+	facetLimit = 20
+	// statsTopAuthors is how many authors the catalog stats rank.
+	statsTopAuthors = 10
+)
+
+// GetBookFacets counts the books matching the list filters by author, decade, genre
+// and language.
+func (s *BookService) GetBookFacets(ctx context.Context, filter *dtos.BookFilter) (_ *dtos.BookFacets, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetBookFacets")
+	defer func() { tracing.End(span, err) }()
+
+	query, err := s.buildBookFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FacetBooks(ctx, query, facetLimit)
+}
+
+// GetBookStats returns the catalog totals, the books per publication year and the
+// authors with the most books.
+func (s *BookService) GetBookStats(ctx context.Context) (_ *dtos.BookStats, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetBookStats")
+	defer func() { tracing.End(span, err) }()
+
+	return s.repo.GetBookStats(ctx, statsTopAuthors)
+}
@@ -0,0 +1,479 @@
+package bookService
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"fiber-app/src/books/dtos"
+	"fiber-app/src/metrics"
+	"fiber-app/src/models"
+	"fiber-app/src/tracing"
+	"fiber-app/src/utils"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultImportBatchSize = 500
+	maxImportBatchSize     = 5000
+	maxImportReportErrors  = 1000
+	maxImportLineBytes     = 1 << 20
+)
+
+// importRow is a single decoded record of an import file.
+type importRow struct {
+	line     int
+	dto      *dtos.CreateDTO
+	err      error
+	errField string
+}
+
+// importReader yields decoded rows one at a time so the upload is never held in memory.
+type importReader interface {
+	Next() (*importRow, error)
+}
+
+// pendingWrite ties a queued bulk operation back to the row that produced it.
+type pendingWrite struct {
+	row   int
+	model mongo.WriteModel
+}
+
+// ImportBooks streams rows from r, validates each one with the CreateDTO rules and
+// writes the valid ones in batches. Rows that fail are reported individually and do
+// not stop the import. progress, if set, is called with the running report after
+// every row.
+func (s *BookService) ImportBooks(ctx context.Context, r io.Reader, opts dtos.ImportOptions, progress func(*dtos.ImportReport)) (_ *dtos.ImportReport, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.ImportBooks")
+	defer func() { tracing.End(span, err) }()
+
+	if err := NormalizeImportOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	reader, err := newImportReader(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dtos.ImportReport{Format: opts.Format, Mode: opts.Mode, DryRun: opts.DryRun, Errors: []dtos.ImportRowError{}}
+	batch := make([]pendingWrite, 0, opts.BatchSize)
+	// authorCache remembers resolved author IDs by "id:" or "name:" key for the whole import.
+	authorCache := map[string]primitive.ObjectID{}
+
+	for {
+		if progress != nil && report.Total > 0 {
+			progress(report)
+		}
+
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Total++
+
+		if row.err != nil {
+			addImportError(report, dtos.ImportRowError{Row: row.line, Field: row.errField, Message: row.err.Error()})
+			report.Failed++
+			continue
+		}
+		book, rowErrs, err := s.validateImportRow(ctx, row, opts, authorCache)
+		if err != nil {
+			return report, err
+		}
+		if len(rowErrs) > 0 {
+			for _, rowErr := range rowErrs {
+				addImportError(report, rowErr)
+			}
+			report.Failed++
+			continue
+		}
+		report.Valid++
+
+		if opts.DryRun {
+			continue
+		}
+
+		batch = append(batch, pendingWrite{row: row.line, model: importWriteModel(book, opts, row.line)})
+		if len(batch) >= opts.BatchSize {
+			if err := s.flushImportBatch(ctx, batch, report); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := s.flushImportBatch(ctx, batch, report); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// NormalizeImportOptions validates the options and fills in defaults.
+func NormalizeImportOptions(opts *dtos.ImportOptions) error {
+	opts.Format = strings.ToLower(opts.Format)
+	switch opts.Format {
+	case dtos.ImportFormatCSV, dtos.ImportFormatNDJSON:
+	case "jsonl":
+		opts.Format = dtos.ImportFormatNDJSON
+	default:
+		return utils.BadRequest("invalid_format", "format must be one of 'csv' or 'ndjson'")
+	}
+
+	if opts.Mode == "" {
+		opts.Mode = dtos.ImportModeInsert
+	}
+	if opts.Mode != dtos.ImportModeInsert && opts.Mode != dtos.ImportModeUpsert {
+		return utils.BadRequest("invalid_mode", "mode must be one of 'insert' or 'upsert'")
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+	if opts.BatchSize > maxImportBatchSize {
+		opts.BatchSize = maxImportBatchSize
+	}
+
+	return nil
+}
+
+func newImportReader(r io.Reader, format string) (importReader, error) {
+	if format == dtos.ImportFormatNDJSON {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxImportLineBytes)
+		return &ndjsonImportReader{scanner: scanner}, nil
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, utils.BadRequest("invalid_file", "CSV file is empty")
+	}
+	if err != nil {
+		return nil, utils.BadRequest("invalid_file", "Failed to read CSV header: "+err.Error())
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"title", "year"} {
+		if _, ok := columns[required]; !ok {
+			return nil, utils.BadRequest("invalid_file", fmt.Sprintf("CSV header is missing the '%s' column", required))
+		}
+	}
+	_, hasAuthors := columns["authors"]
+	_, hasAuthor := columns["author"]
+	_, hasAuthorIDs := columns["authorids"]
+	if !hasAuthors && !hasAuthor && !hasAuthorIDs {
+		return nil, utils.BadRequest("invalid_file", "CSV header needs an 'authors', 'author' or 'authorIds' column")
+	}
+
+	return &csvImportReader{reader: cr, columns: columns, line: 1}, nil
+}
+
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	line    int
+}
+
+func (r *csvImportReader) Next() (*importRow, error) {
+	record, err := r.reader.Read()
+	r.line++
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &importRow{line: r.line, err: parseErr.Err}, nil
+		}
+		return nil, err
+	}
+
+	dto := &dtos.CreateDTO{
+		Title:       r.column(record, "title"),
+		AuthorIDs:   r.list(record, "authorids"),
+		AuthorNames: append(r.list(record, "authors"), r.list(record, "author")...),
+		ISBN:        r.column(record, "isbn"),
+		Publisher:   r.column(record, "publisher"),
+		Language:    r.column(record, "language"),
+		Description: r.column(record, "description"),
+	}
+	for _, field := range []struct {
+		name string
+		dst  *int
+	}{{"year", &dto.Year}, {"pages", &dto.Pages}} {
+		value := r.column(record, field.name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return &importRow{line: r.line, errField: field.name, err: fmt.Errorf("%s must be a whole number", field.name)}, nil
+		}
+		*field.dst = n
+	}
+	dto.Genres = r.list(record, "genres")
+
+	return &importRow{line: r.line, dto: dto}, nil
+}
+
+// list splits a multi-valued cell on ';' or '|'.
+func (r *csvImportReader) list(record []string, name string) []string {
+	value := r.column(record, name)
+	if value == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(c rune) bool { return c == ';' || c == '|' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (r *csvImportReader) column(record []string, name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+type ndjsonImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonImportReader) Next() (*importRow, error) {
+	for r.scanner.Scan() {
+		r.line++
+		text := strings.TrimSpace(r.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		dto := new(dtos.CreateDTO)
+		if err := json.Unmarshal([]byte(text), dto); err != nil {
+			return &importRow{line: r.line, err: errors.New("invalid JSON: " + err.Error())}, nil
+		}
+		return &importRow{line: r.line, dto: dto}, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, utils.BadRequest("invalid_file", fmt.Sprintf("line %d exceeds %d bytes", r.line+1, maxImportLineBytes))
+		}
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// validateImportRow applies the CreateDTO rules and returns either the book to write
+// or one error per failing field. Authors are only resolved, and unknown names only
+// created, when the import is not a dry run. The error is set for failures that
+// should abort the whole import.
+func (s *BookService) validateImportRow(ctx context.Context, row *importRow, opts dtos.ImportOptions, authorCache map[string]primitive.ObjectID) (*models.Book, []dtos.ImportRowError, error) {
+	var rowErrs []dtos.ImportRowError
+
+	if err := row.dto.Validate(); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			for _, e := range validationErrs {
+				rowErrs = append(rowErrs, dtos.ImportRowError{Row: row.line, Field: e.Field(), Message: utils.FormatValidationError(validator.ValidationErrors{e})})
+			}
+		} else {
+			rowErrs = append(rowErrs, dtos.ImportRowError{Row: row.line, Message: err.Error()})
+		}
+	}
+
+	if opts.Mode == dtos.ImportModeUpsert && row.dto.ISBN == "" {
+		rowErrs = append(rowErrs, dtos.ImportRowError{Row: row.line, Field: "isbn", Message: "isbn is required in upsert mode"})
+	}
+	if len(rowErrs) > 0 {
+		return nil, rowErrs, nil
+	}
+
+	var authorIDs []primitive.ObjectID
+	if !opts.DryRun {
+		var err error
+		authorIDs, err = s.resolveImportAuthors(ctx, row.dto, authorCache)
+		if err != nil {
+			if e, ok := err.(*utils.Error); ok {
+				return nil, []dtos.ImportRowError{{Row: row.line, Field: "authors", Message: e.Message}}, nil
+			}
+			return nil, nil, err
+		}
+	}
+
+	return newBookFromDTO(row.dto, authorIDs), nil, nil
+}
+
+// resolveImportAuthors resolves a row's authors, consulting the per-import cache so
+// a catalog with thousands of books by the same author only looks it up once.
+func (s *BookService) resolveImportAuthors(ctx context.Context, dto *dtos.CreateDTO, cache map[string]primitive.ObjectID) ([]primitive.ObjectID, error) {
+	ids := make([]primitive.ObjectID, 0, len(dto.AuthorIDs)+len(dto.AuthorNames))
+	resolve := func(key string, id, name string) error {
+		if cached, ok := cache[key]; ok {
+			ids = append(ids, cached)
+			return nil
+		}
+		var idList, nameList []string
+		if id != "" {
+			idList = []string{id}
+		} else {
+			nameList = []string{name}
+		}
+		resolved, err := s.authors.ResolveAuthorIDs(ctx, idList, nameList)
+		if err != nil {
+			return err
+		}
+		if len(resolved) == 1 {
+			cache[key] = resolved[0]
+			ids = append(ids, resolved[0])
+		}
+		return nil
+	}
+
+	for _, id := range dto.AuthorIDs {
+		if err := resolve("id:"+id, id, ""); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range dto.AuthorNames {
+		if err := resolve("name:"+utils.NormalizePersonName(name), "", name); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the first occurrence of each author.
+	seen := map[primitive.ObjectID]bool{}
+	unique := ids[:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
+
+func importWriteModel(book *models.Book, opts dtos.ImportOptions, row int) mongo.WriteModel {
+	if opts.Mode == dtos.ImportModeUpsert {
+		// Only the columns the row filled in are set, so an update keeps the book's
+		// other fields, such as its tags, cover and copy and rating counts. createdAt
+		// is only set when the upsert inserts. Merged books are never matched.
+		return mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"isbn": book.ISBN, "deletedAt": bson.M{"$exists": false}}).
+			SetUpdate(bson.M{"$set": importUpsertSet(book), "$setOnInsert": bson.M{"createdAt": book.CreatedAt}}).
+			SetUpsert(true)
+	}
+	if opts.RunKey == "" {
+		return mongo.NewInsertOneModel().SetDocument(book)
+	}
+
+	// A keyed insert only inserts when no book carries the row's key yet. The
+	// inserted book takes the key from the filter.
+	return mongo.NewUpdateOneModel().
+		SetFilter(bson.M{"importKey": opts.RunKey + ":" + strconv.Itoa(row)}).
+		SetUpdate(bson.M{"$setOnInsert": book}).
+		SetUpsert(true)
+}
+
+// importUpsertSet returns the fields of a book built from an import row that the
+// row gave a value for. Title, authors and year are required, so they always are.
+func importUpsertSet(book *models.Book) bson.M {
+	set := bson.M{
+		"title":       book.Title,
+		"titleFolded": book.TitleFolded,
+		"authorIds":   book.AuthorIDs,
+		"isbn":        book.ISBN,
+		"year":        book.Year,
+		"updatedAt":   book.UpdatedAt,
+	}
+	if book.Publisher != "" {
+		set["publisher"] = book.Publisher
+	}
+	if book.Language != "" {
+		set["language"] = book.Language
+	}
+	if book.Pages != 0 {
+		set["pages"] = book.Pages
+	}
+	if len(book.Genres) > 0 {
+		set["genres"] = book.Genres
+	}
+	if book.Description != "" {
+		set["description"] = book.Description
+	}
+	return set
+}
+
+// flushImportBatch writes one batch and folds per-operation failures into the report.
+func (s *BookService) flushImportBatch(ctx context.Context, batch []pendingWrite, report *dtos.ImportReport) error {
+	operations := make([]mongo.WriteModel, len(batch))
+	for i, w := range batch {
+		operations[i] = w.model
+	}
+
+	res, err := s.repo.BulkWriteBooks(ctx, operations, false)
+	if res != nil {
+		if report.Mode == dtos.ImportModeInsert {
+			// Keyed inserts are upserts on the row key, and a row they match was
+			// inserted by an earlier run of the same import.
+			report.Inserted += res.InsertedCount + res.UpsertedCount + res.MatchedCount
+		} else {
+			report.Upserted += res.UpsertedCount
+			report.Modified += res.ModifiedCount
+		}
+		metrics.BooksCreated.Add(float64(res.InsertedCount + res.UpsertedCount))
+	}
+	if err == nil {
+		return nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || len(bulkErr.WriteErrors) == 0 {
+		return err
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		row := 0
+		if writeErr.Index >= 0 && writeErr.Index < len(batch) {
+			row = batch[writeErr.Index].row
+		}
+		addImportError(report, dtos.ImportRowError{Row: row, Message: writeErr.Message})
+		report.Failed++
+	}
+	return nil
+}
+
+func addImportError(report *dtos.ImportReport, rowErr dtos.ImportRowError) {
+	if len(report.Errors) >= maxImportReportErrors {
+		report.Truncated = true
+		return
+	}
+	report.Errors = append(report.Errors, rowErr)
+}
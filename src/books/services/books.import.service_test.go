@@ -0,0 +1,170 @@
+package bookService
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"fiber-app/src/books/dtos"
+	"fiber-app/src/utils"
+)
+
+// readRows returns every row the reader yields.
+func readRows(t *testing.T, r importReader) []*importRow {
+	t.Helper()
+	var rows []*importRow
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return rows
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVImportReader(t *testing.T) {
+	input := "\ufeffTitle, Authors ,authorIds,ISBN,year,pages,genres,language\n" +
+		"Dune,Frank Herbert,,978-0-441-17271-9,1965,412,Science Fiction; classics ,en\n" +
+		"Good Omens,Terry Pratchett|Neil Gaiman,507f1f77bcf86cd799439011,,1990,,,\n" +
+		"Short,Someone\n" +
+		"Bad Year,Someone,,,nineteen,,,\n"
+
+	r, err := newImportReader(strings.NewReader(input), dtos.ImportFormatCSV)
+	if err != nil {
+		t.Fatalf("newImportReader() error = %v", err)
+	}
+	rows := readRows(t, r)
+
+	want := []*importRow{
+		{line: 2, dto: &dtos.CreateDTO{
+			Title:       "Dune",
+			AuthorNames: []string{"Frank Herbert"},
+			ISBN:        "978-0-441-17271-9",
+			Year:        1965,
+			Pages:       412,
+			Genres:      []string{"Science Fiction", "classics"},
+			Language:    "en",
+		}},
+		{line: 3, dto: &dtos.CreateDTO{
+			Title:       "Good Omens",
+			AuthorIDs:   []string{"507f1f77bcf86cd799439011"},
+			AuthorNames: []string{"Terry Pratchett", "Neil Gaiman"},
+			Year:        1990,
+		}},
+		{line: 4, dto: &dtos.CreateDTO{Title: "Short", AuthorNames: []string{"Someone"}}},
+	}
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 4", len(rows))
+	}
+	for i, w := range want {
+		if !reflect.DeepEqual(rows[i], w) {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i].dto, w.dto)
+		}
+	}
+
+	bad := rows[3]
+	if bad.line != 5 || bad.errField != "year" || bad.err == nil || bad.dto != nil {
+		t.Errorf("bad year row = %+v, want a year error on line 5", bad)
+	}
+}
+
+func TestCSVImportReaderHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"empty file", "", "CSV file is empty"},
+		{"no title", "year,authors\n", "CSV header is missing the 'title' column"},
+		{"no year", "title,authors\n", "CSV header is missing the 'year' column"},
+		{"no authors", "title,year\n", "CSV header needs an 'authors', 'author' or 'authorIds' column"},
+		{"author column", "title,year,author\n", ""},
+		{"author ids column", "title,year,authorIds\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newImportReader(strings.NewReader(tt.input), dtos.ImportFormatCSV)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("newImportReader() error = %v", err)
+				}
+				return
+			}
+			var e *utils.Error
+			if !errors.As(err, &e) || e.Err != "invalid_file" || e.Message != tt.wantErr {
+				t.Errorf("newImportReader() error = %v, want invalid_file %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNDJSONImportReader(t *testing.T) {
+	input := `{"title":"Dune","authorNames":["Frank Herbert"],"year":1965}` + "\n" +
+		"\n" +
+		`{"title":` + "\n" +
+		`  {"title":"Emma","authorIds":["507f1f77bcf86cd799439011"],"year":1815,"genres":["romance"]}  ` + "\n"
+
+	r, err := newImportReader(strings.NewReader(input), dtos.ImportFormatNDJSON)
+	if err != nil {
+		t.Fatalf("newImportReader() error = %v", err)
+	}
+	rows := readRows(t, r)
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+
+	want := map[int]*dtos.CreateDTO{
+		0: {Title: "Dune", AuthorNames: []string{"Frank Herbert"}, Year: 1965},
+		2: {Title: "Emma", AuthorIDs: []string{"507f1f77bcf86cd799439011"}, Year: 1815, Genres: []string{"romance"}},
+	}
+	for i, dto := range want {
+		if rows[i].err != nil || !reflect.DeepEqual(rows[i].dto, dto) {
+			t.Errorf("row %d = %+v (err %v), want %+v", i, rows[i].dto, rows[i].err, dto)
+		}
+	}
+	// Blank lines are skipped but still counted.
+	if rows[1].line != 3 || rows[1].err == nil || !strings.HasPrefix(rows[1].err.Error(), "invalid JSON") {
+		t.Errorf("row 1 = line %d, err %v; want invalid JSON on line 3", rows[1].line, rows[1].err)
+	}
+	if rows[2].line != 4 {
+		t.Errorf("row 2 is on line %d, want 4", rows[2].line)
+	}
+}
+
+func TestNormalizeImportOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    dtos.ImportOptions
+		want    dtos.ImportOptions
+		wantErr string
+	}{
+		{"defaults", dtos.ImportOptions{Format: "CSV"}, dtos.ImportOptions{Format: dtos.ImportFormatCSV, Mode: dtos.ImportModeInsert, BatchSize: defaultImportBatchSize}, ""},
+		{"jsonl is ndjson", dtos.ImportOptions{Format: "jsonl", Mode: dtos.ImportModeUpsert, BatchSize: 10}, dtos.ImportOptions{Format: dtos.ImportFormatNDJSON, Mode: dtos.ImportModeUpsert, BatchSize: 10}, ""},
+		{"batch size capped", dtos.ImportOptions{Format: "ndjson", BatchSize: maxImportBatchSize + 1}, dtos.ImportOptions{Format: dtos.ImportFormatNDJSON, Mode: dtos.ImportModeInsert, BatchSize: maxImportBatchSize}, ""},
+		{"unknown format", dtos.ImportOptions{Format: "xml"}, dtos.ImportOptions{}, "invalid_format"},
+		{"unknown mode", dtos.ImportOptions{Format: "csv", Mode: "replace"}, dtos.ImportOptions{}, "invalid_mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			err := NormalizeImportOptions(&opts)
+			if tt.wantErr != "" {
+				var e *utils.Error
+				if !errors.As(err, &e) || e.Err != tt.wantErr {
+					t.Errorf("NormalizeImportOptions() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || opts != tt.want {
+				t.Errorf("NormalizeImportOptions() = %+v, %v; want %+v", opts, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package bookService
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-app/src/auth"
+	"fiber-app/src/books/dtos"
+	jobService "fiber-app/src/jobs/services"
+	"fiber-app/src/models"
+	"fiber-app/src/tracing"
+)
+
+// Background job types owned by the books module.
+const (
+	JobTypeImport = "books.import"
+	JobTypeExport = "books.export"
+)
+
+// RegisterJobHandlers makes this instance able to run book import and export jobs.
+func (s *BookService) RegisterJobHandlers() {
+	jobService.RegisterHandler(JobTypeImport, s.runImportJob)
+	jobService.RegisterHandler(JobTypeExport, s.runExportJob)
+}
+
+// EnqueueImport validates the options, stores the upload and queues an import job
+// on behalf of user.
+func (s *BookService) EnqueueImport(ctx context.Context, user *auth.User, r io.Reader, filename string, opts dtos.ImportOptions) (_ *models.Job, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.EnqueueImport")
+	defer func() { tracing.End(span, err) }()
+
+	if err := NormalizeImportOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{
+		"format":    opts.Format,
+		"mode":      opts.Mode,
+		"dryRun":    strconv.FormatBool(opts.DryRun),
+		"batchSize": strconv.Itoa(opts.BatchSize),
+	}
+	if filename == "" {
+		filename = "import." + opts.Format
+	}
+
+	return s.jobs.Enqueue(ctx, user, JobTypeImport, params, r, filename)
+}
+
+// EnqueueExport queues an export job whose result user can download once it finishes.
+func (s *BookService) EnqueueExport(ctx context.Context, user *auth.User, filter *dtos.BookFilter, format string) (_ *models.Job, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.EnqueueExport")
+	defer func() { tracing.End(span, err) }()
+
+	if _, _, err := ExportContentType(format); err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{
+		"format":    strings.ToLower(format),
+		"title":     filter.Title,
+		"author":    filter.Author,
+		"authorId":  filter.AuthorID,
+		"year":      strconv.Itoa(filter.Year),
+		"isbn":      filter.ISBN,
+		"publisher": filter.Publisher,
+		"language":  filter.Language,
+		"genre":     filter.Genre,
+		"tag":       filter.Tag,
+	}
+
+	return s.jobs.Enqueue(ctx, user, JobTypeExport, params, nil, "")
+}
+
+func (s *BookService) runImportJob(ctx context.Context, run *jobService.Run) error {
+	input, err := run.Input()
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	params := run.Job.Params
+	dryRun, _ := strconv.ParseBool(params["dryRun"])
+	batchSize, _ := strconv.Atoi(params["batchSize"])
+	// A retried job reads the file from the start again; keying its rows on the job
+	// keeps the rows an earlier attempt inserted from being inserted twice.
+	opts := dtos.ImportOptions{Format: params["format"], Mode: params["mode"], DryRun: dryRun, BatchSize: batchSize, RunKey: run.Job.ID.Hex()}
+
+	// Progress is reported after every row, so its error list is only copied when it
+	// is about to be saved.
+	report, err := s.ImportBooks(ctx, input, opts, func(report *dtos.ImportReport) {
+		if run.Due() {
+			reportImportProgress(ctx, run, report)
+		}
+	})
+	if report != nil {
+		reportImportProgress(ctx, run, report)
+	}
+	return err
+}
+
+func reportImportProgress(ctx context.Context, run *jobService.Run, report *dtos.ImportReport) {
+	counts := map[string]int64{
+		"total":    int64(report.Total),
+		"valid":    int64(report.Valid),
+		"failed":   int64(report.Failed),
+		"inserted": report.Inserted,
+		"upserted": report.Upserted,
+		"modified": report.Modified,
+	}
+	errs := make([]models.JobError, len(report.Errors))
+	for i, e := range report.Errors {
+		errs[i] = models.JobError{Row: e.Row, Field: e.Field, Message: e.Message}
+	}
+	run.Report(ctx, int64(report.Total), counts, errs)
+}
+
+func (s *BookService) runExportJob(ctx context.Context, run *jobService.Run) error {
+	params := run.Job.Params
+	format := params["format"]
+	year, _ := strconv.Atoi(params["year"])
+	filter := &dtos.BookFilter{
+		Title:     params["title"],
+		Author:    params["author"],
+		AuthorID:  params["authorId"],
+		Year:      year,
+		ISBN:      params["isbn"],
+		Publisher: params["publisher"],
+		Language:  params["language"],
+		Genre:     params["genre"],
+		Tag:       params["tag"],
+	}
+
+	contentType, extension, err := ExportContentType(format)
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("books-%s.%s", time.Now().UTC().Format("20060102-150405"), extension)
+	result, err := run.CreateResult(filename, contentType)
+	if err != nil {
+		return err
+	}
+
+	var written int64
+	err = s.ExportBooks(ctx, filter, format, result, func(n int64) {
+		written = n
+		run.Report(ctx, n, map[string]int64{"exported": n}, nil)
+	})
+	if err != nil {
+		result.Abort()
+		return err
+	}
+	run.Report(ctx, written, map[string]int64{"exported": written}, nil)
+
+	return result.Close()
+}
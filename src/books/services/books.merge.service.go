@@ -0,0 +1,361 @@
+package bookService
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"fiber-app/src/books/dtos"
+	"fiber-app/src/books/repository"
+	"fiber-app/src/common"
+	"fiber-app/src/metrics"
+	"fiber-app/src/models"
+	"fiber-app/src/tracing"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultDuplicateThreshold = 0.85
+	defaultDuplicateLimit     = 100
+)
+
+func newMergeRepository() repository.MergeRepository {
+	return repository.NewMergeRepository(
+		common.GetDBCollection("books"),
+		common.GetDBCollection("copies"),
+		common.GetDBCollection("loans"),
+		common.GetDBCollection("holds"),
+		common.GetDBCollection("reviews"),
+		common.GetDBCollection("lists"),
+	)
+}
+
+// FindDuplicates groups live books that look like the same title: books sharing an
+// ISBN, and books that share an author and whose folded titles are at least
+// Threshold similar. Books without authors are only compared with others starting
+// with the same word. Groups are transitive, so A~B and B~C puts all three together.
+func (s *BookService) FindDuplicates(ctx context.Context, query *dtos.DuplicateQuery) (_ []dtos.DuplicateGroup, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.FindDuplicates")
+	defer func() { tracing.End(span, err) }()
+
+	if err := query.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+	threshold := query.Threshold
+	if threshold == 0 {
+		threshold = defaultDuplicateThreshold
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultDuplicateLimit
+	}
+
+	books, err := s.merges.GetDuplicateCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := newBookGroups(len(books))
+	byISBN := map[string]int{}
+	buckets := map[string][]int{}
+	for i := range books {
+		book := &books[i]
+		if book.TitleFolded == "" {
+			book.TitleFolded = utils.FoldText(book.Title)
+		}
+		if book.ISBN != "" {
+			if j, ok := byISBN[book.ISBN]; ok {
+				groups.union(i, j, dtos.DuplicateReasonISBN, 1)
+			} else {
+				byISBN[book.ISBN] = i
+			}
+		}
+		for _, key := range duplicateBuckets(book) {
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	for _, members := range buckets {
+		for a := 0; a < len(members); a++ {
+			for b := a + 1; b < len(members); b++ {
+				i, j := members[a], members[b]
+				if groups.find(i) == groups.find(j) {
+					continue
+				}
+				if score := utils.TextSimilarity(books[i].TitleFolded, books[j].TitleFolded); score >= threshold {
+					groups.union(i, j, dtos.DuplicateReasonTitle, score)
+				}
+			}
+		}
+	}
+
+	result := groups.collect(books)
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// duplicateBuckets returns the keys of the candidate buckets a book is compared in.
+func duplicateBuckets(book *models.Book) []string {
+	if len(book.AuthorIDs) == 0 {
+		word := book.TitleFolded
+		for i, r := range word {
+			if r == ' ' {
+				word = word[:i]
+				break
+			}
+		}
+		return []string{"title:" + word}
+	}
+	keys := make([]string, len(book.AuthorIDs))
+	for i, id := range book.AuthorIDs {
+		keys[i] = "author:" + id.Hex()
+	}
+	return keys
+}
+
+// bookGroups is a union-find over book indexes that remembers why each group formed.
+type bookGroups struct {
+	parent     []int
+	reason     map[int]string
+	similarity map[int]float64
+}
+
+func newBookGroups(n int) *bookGroups {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &bookGroups{parent: parent, reason: map[int]string{}, similarity: map[int]float64{}}
+}
+
+func (g *bookGroups) find(i int) int {
+	for g.parent[i] != i {
+		g.parent[i] = g.parent[g.parent[i]]
+		i = g.parent[i]
+	}
+	return i
+}
+
+// union joins the groups of i and j. A group keeps its weakest similarity, and is
+// reported as an ISBN group only if every link in it was an ISBN match.
+func (g *bookGroups) union(i, j int, reason string, score float64) {
+	ri, rj := g.find(i), g.find(j)
+	if ri == rj {
+		return
+	}
+	g.parent[rj] = ri
+
+	merged, similarity := reason, score
+	for _, root := range []int{ri, rj} {
+		if r, ok := g.reason[root]; ok {
+			if r != merged {
+				merged = dtos.DuplicateReasonTitle
+			}
+			if g.similarity[root] < similarity {
+				similarity = g.similarity[root]
+			}
+		}
+	}
+	g.reason[ri], g.similarity[ri] = merged, similarity
+	delete(g.reason, rj)
+	delete(g.similarity, rj)
+}
+
+// collect returns the groups with more than one book, largest first and then in
+// the order of their oldest book.
+func (g *bookGroups) collect(books []models.Book) []dtos.DuplicateGroup {
+	members := map[int][]models.Book{}
+	var roots []int
+	for i := range books {
+		root := g.find(i)
+		if _, ok := g.reason[root]; !ok {
+			continue
+		}
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], books[i])
+	}
+
+	sort.SliceStable(roots, func(a, b int) bool {
+		return len(members[roots[a]]) > len(members[roots[b]])
+	})
+	groups := make([]dtos.DuplicateGroup, 0, len(roots))
+	for _, root := range roots {
+		groups = append(groups, dtos.DuplicateGroup{Reason: g.reason[root], Similarity: g.similarity[root], Books: members[root]})
+	}
+	return groups
+}
+
+// MergeBooks folds the source books into the target in one transaction. Fields the
+// target lacks are taken from the first source that has them; authors, genres and
+// tags are combined. Copies, loans, open holds, reviews and list entries move to the
+// target, copy counts are added up and the rating is recomputed from the reviews
+// that remain. The sources are soft-deleted and record the target in mergedInto.
+// The target's cover, if any, is kept; source covers are not moved.
+func (s *BookService) MergeBooks(ctx context.Context, dto *dtos.MergeDTO) (_ *dtos.MergeResult, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.MergeBooks")
+	defer func() { tracing.End(span, err) }()
+
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+	targetID, err := primitive.ObjectIDFromHex(dto.TargetID)
+	if err != nil {
+		return nil, utils.Validation("invalid target id " + dto.TargetID)
+	}
+	var sourceIDs []primitive.ObjectID
+	seen := map[primitive.ObjectID]bool{targetID: true}
+	for _, raw := range dto.SourceIDs {
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			return nil, utils.Validation("invalid source id " + raw)
+		}
+		if id == targetID {
+			return nil, utils.Validation("a book cannot be merged into itself")
+		}
+		if !seen[id] {
+			seen[id] = true
+			sourceIDs = append(sourceIDs, id)
+		}
+	}
+
+	result := &dtos.MergeResult{Merged: len(sourceIDs)}
+	_, err = s.merges.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// The callback can be retried, so start from zero each time.
+		*result = dtos.MergeResult{Merged: len(sourceIDs)}
+
+		books, err := s.merges.GetLiveBooks(sessCtx, append([]primitive.ObjectID{targetID}, sourceIDs...))
+		if err != nil {
+			return nil, err
+		}
+		byID := map[primitive.ObjectID]*models.Book{}
+		for i := range books {
+			byID[books[i].ID] = &books[i]
+		}
+		target, ok := byID[targetID]
+		if !ok {
+			return nil, utils.NotFound("book " + targetID.Hex() + " not found")
+		}
+		sources := make([]*models.Book, 0, len(sourceIDs))
+		for _, id := range sourceIDs {
+			source, ok := byID[id]
+			if !ok {
+				return nil, utils.NotFound("book " + id.Hex() + " not found")
+			}
+			sources = append(sources, source)
+		}
+
+		now := time.Now().UTC()
+		set, inc := mergedBookFields(target, sources)
+		set["updatedAt"] = now
+
+		// Sources go first so their ISBNs are free before the target takes one.
+		if err := s.merges.SoftDeleteBooks(sessCtx, sourceIDs, targetID, now); err != nil {
+			return nil, err
+		}
+		if result.Copies, err = s.merges.RepointCopies(sessCtx, sourceIDs, targetID); err != nil {
+			return nil, err
+		}
+		if result.Loans, err = s.merges.RepointLoans(sessCtx, sourceIDs, targetID); err != nil {
+			return nil, err
+		}
+		if result.Holds, err = s.merges.RepointHolds(sessCtx, sourceIDs, targetID, now); err != nil {
+			return nil, err
+		}
+		if result.Reviews, err = s.merges.RepointReviews(sessCtx, sourceIDs, targetID); err != nil {
+			return nil, err
+		}
+		if result.Lists, err = s.merges.RepointLists(sessCtx, sourceIDs, targetID, now); err != nil {
+			return nil, err
+		}
+
+		count, sum, err := s.merges.GetReviewTotals(sessCtx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		set["ratingCount"], set["ratingSum"], set["ratingAverage"] = count, sum, 0.0
+		if count > 0 {
+			set["ratingAverage"] = float64(sum) / float64(count)
+		}
+
+		return nil, s.merges.UpdateMergedBook(sessCtx, targetID, set, inc)
+	})
+	if err != nil {
+		return nil, err
+	}
+	suggestions.Purge()
+	// Merged books leave the catalog just like deleted ones.
+	metrics.BooksDeleted.Add(float64(result.Merged))
+
+	if result.Book, err = s.repo.GetBookByID(ctx, targetID.Hex()); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mergedBookFields returns the $set and $inc that fold the sources into the target.
+func mergedBookFields(target *models.Book, sources []*models.Book) (bson.M, bson.M) {
+	set := bson.M{}
+	authorIDs := append([]primitive.ObjectID{}, target.AuthorIDs...)
+	genres := append([]string{}, target.Genres...)
+	tags := append([]string{}, target.Tags...)
+	var copiesTotal, copiesAvailable int
+
+	fill := func(field string, empty bool, value interface{}, valueEmpty bool) {
+		if _, done := set[field]; !done && empty && !valueEmpty {
+			set[field] = value
+		}
+	}
+	for _, source := range sources {
+		fill("isbn", target.ISBN == "", source.ISBN, source.ISBN == "")
+		fill("year", target.Year == 0, source.Year, source.Year == 0)
+		fill("publisher", target.Publisher == "", source.Publisher, source.Publisher == "")
+		fill("language", target.Language == "", source.Language, source.Language == "")
+		fill("pages", target.Pages == 0, source.Pages, source.Pages == 0)
+		fill("description", target.Description == "", source.Description, source.Description == "")
+
+		authorIDs = appendMissing(authorIDs, source.AuthorIDs...)
+		genres = appendMissing(genres, source.Genres...)
+		tags = appendMissing(tags, source.Tags...)
+		copiesTotal += source.CopiesTotal
+		copiesAvailable += source.CopiesAvailable
+	}
+
+	set["authorIds"] = authorIDs
+	if len(genres) > 0 {
+		set["genres"] = genres
+	}
+	if len(tags) > 0 {
+		set["tags"] = tags
+	}
+	inc := bson.M{}
+	if copiesTotal != 0 || copiesAvailable != 0 {
+		inc["copiesTotal"] = copiesTotal
+		inc["copiesAvailable"] = copiesAvailable
+	}
+	return set, inc
+}
+
+// appendMissing appends the values not already in list, keeping their order.
+func appendMissing[T comparable](list []T, values ...T) []T {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
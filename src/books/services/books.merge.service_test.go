@@ -0,0 +1,109 @@
+package bookService
+
+import (
+	"reflect"
+	"testing"
+
+	"fiber-app/src/books/dtos"
+	"fiber-app/src/models"
+)
+
+type link struct {
+	i, j   int
+	reason string
+	score  float64
+}
+
+func TestBookGroups(t *testing.T) {
+	books := make([]models.Book, 6)
+	for i := range books {
+		books[i].Title = string(rune('a' + i))
+	}
+
+	tests := []struct {
+		name  string
+		links []link
+		want  []dtos.DuplicateGroup
+	}{
+		{
+			name: "no links",
+			want: []dtos.DuplicateGroup{},
+		},
+		{
+			name:  "isbn pair",
+			links: []link{{0, 3, dtos.DuplicateReasonISBN, 1}},
+			want:  []dtos.DuplicateGroup{{Reason: dtos.DuplicateReasonISBN, Similarity: 1, Books: []models.Book{books[0], books[3]}}},
+		},
+		{
+			name: "links chain into one group with the weakest similarity",
+			links: []link{
+				{0, 1, dtos.DuplicateReasonTitle, 0.95},
+				{1, 2, dtos.DuplicateReasonTitle, 0.9},
+			},
+			want: []dtos.DuplicateGroup{{Reason: dtos.DuplicateReasonTitle, Similarity: 0.9, Books: books[0:3]}},
+		},
+		{
+			name: "mixed reasons make a title group",
+			links: []link{
+				{0, 1, dtos.DuplicateReasonISBN, 1},
+				{2, 1, dtos.DuplicateReasonTitle, 0.92},
+			},
+			want: []dtos.DuplicateGroup{{Reason: dtos.DuplicateReasonTitle, Similarity: 0.92, Books: books[0:3]}},
+		},
+		{
+			name: "repeated link changes nothing",
+			links: []link{
+				{4, 5, dtos.DuplicateReasonISBN, 1},
+				{5, 4, dtos.DuplicateReasonTitle, 0.9},
+			},
+			want: []dtos.DuplicateGroup{{Reason: dtos.DuplicateReasonISBN, Similarity: 1, Books: books[4:6]}},
+		},
+		{
+			name: "largest group first, then oldest book first",
+			links: []link{
+				{1, 4, dtos.DuplicateReasonISBN, 1},
+				{2, 3, dtos.DuplicateReasonTitle, 0.9},
+				{3, 5, dtos.DuplicateReasonTitle, 0.95},
+				{0, 0, dtos.DuplicateReasonISBN, 1},
+			},
+			want: []dtos.DuplicateGroup{
+				{Reason: dtos.DuplicateReasonTitle, Similarity: 0.9, Books: []models.Book{books[2], books[3], books[5]}},
+				{Reason: dtos.DuplicateReasonISBN, Similarity: 1, Books: []models.Book{books[1], books[4]}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := newBookGroups(len(books))
+			for _, l := range tt.links {
+				groups.union(l.i, l.j, l.reason, l.score)
+			}
+			if got := groups.collect(books); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendMissing(t *testing.T) {
+	tests := []struct {
+		name   string
+		list   []string
+		values []string
+		want   []string
+	}{
+		{"nothing to add", []string{"a"}, nil, []string{"a"}},
+		{"into empty", nil, []string{"a", "b"}, []string{"a", "b"}},
+		{"skips present values", []string{"a", "b"}, []string{"b", "c", "a"}, []string{"a", "b", "c"}},
+		{"skips repeated values", []string{"a"}, []string{"c", "c"}, []string{"a", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appendMissing(tt.list, tt.values...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("appendMissing(%v, %v) = %v, want %v", tt.list, tt.values, got, tt.want)
+			}
+		})
+	}
+}
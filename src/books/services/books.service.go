@@ -2,20 +2,37 @@ package bookService
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
 
+	authorService "fiber-app/src/authors/services"
 	"fiber-app/src/books/dtos"
 	"fiber-app/src/books/repository"
 	"fiber-app/src/common"
+	jobService "fiber-app/src/jobs/services"
+	"fiber-app/src/logging"
+	"fiber-app/src/metrics"
 	"fiber-app/src/models"
+	"fiber-app/src/storage"
+	"fiber-app/src/tracing"
 	"fiber-app/src/utils"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/text/language"
 )
 
+var logger = logging.For("books")
+
 type BookService struct {
-	repo repository.BookRepository
+	repo    repository.BookRepository
+	jobs    *jobService.JobService
+	authors *authorService.AuthorService
+	covers  storage.BlobStore
+	merges  repository.MergeRepository
 }
 
 // NewBookService initializes the repository and returns a new BookService instance.
@@ -26,69 +43,346 @@ func NewBookService() *BookService {
 	// Initialize the repository with the collection
 	repo := repository.NewBookRepository(dbCollection)
 
+	// Cover images fall back to GridFS when BLOB_STORE is invalid
+	covers, err := storage.NewBlobStore(coverBucket)
+	if err != nil {
+		logger.Error("opening cover store, using GridFS", logging.Err(err))
+		covers = storage.NewGridFSStore(coverBucket)
+	}
+
 	// Return the service with the repository
-	return &BookService{repo: repo}
+	return &BookService{repo: repo, jobs: jobService.NewJobService(), authors: authorService.NewAuthorService(), covers: covers, merges: newMergeRepository()}
+}
+
+func (s *BookService) GetAllBooks(ctx context.Context, filter *dtos.BookFilter) (_ []models.Book, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetAllBooks")
+	defer func() { tracing.End(span, err) }()
+
+	query, err := s.buildBookFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var sort bson.D
+	if filter != nil {
+		if sort, err = bookSort(filter.Sort); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetAllBooks(ctx, query, sort)
 }
 
-func (s *BookService) GetAllBooks(ctx context.Context) ([]models.Book, error) {
-	return s.repo.GetAllBooks(ctx)
+// bookSortFields maps the sort keys of the list endpoint to the fields they order by.
+// Books tied on rating are ordered by how many ratings they have.
+var bookSortFields = map[string][]string{
+	"title":     {"title"},
+	"year":      {"year"},
+	"createdAt": {"createdAt"},
+	"rating":    {"ratingAverage", "ratingCount"},
+}
+
+// bookSort turns a sort key such as "-rating" into a sort document. Ties are broken
+// by _id so pages stay stable.
+func bookSort(key string) (bson.D, error) {
+	if key == "" {
+		return nil, nil
+	}
+	direction := 1
+	if strings.HasPrefix(key, "-") {
+		direction = -1
+		key = key[1:]
+	}
+
+	fields, ok := bookSortFields[key]
+	if !ok {
+		return nil, utils.BadRequest("invalid_sort", "sort must be one of title, year, createdAt, rating, optionally prefixed with -")
+	}
+	sort := bson.D{}
+	for _, field := range fields {
+		sort = append(sort, bson.E{Key: field, Value: direction})
+	}
+	return append(sort, bson.E{Key: "_id", Value: direction}), nil
+}
+
+// GetBooksByAuthor lists the books that reference the given author.
+func (s *BookService) GetBooksByAuthor(ctx context.Context, authorID string) (_ []models.Book, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetBooksByAuthor")
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := s.authors.GetAuthorByID(ctx, authorID); err != nil {
+		return nil, err
+	}
+	return s.GetAllBooks(ctx, &dtos.BookFilter{AuthorID: authorID})
 }
 
-func (s *BookService) GetBookByID(ctx context.Context, id string) (*models.Book, error) {
+// buildBookFilter turns the list query parameters into a Mongo filter. Text fields
+// match case-insensitively anywhere in the value; the rest must match exactly. The
+// author name is matched against the authors collection first.
+func (s *BookService) buildBookFilter(ctx context.Context, filter *dtos.BookFilter) (bson.M, error) {
+	query := bson.M{}
+	if filter == nil {
+		return query, nil
+	}
+	if filter.Title != "" {
+		query["title"] = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Title), Options: "i"}
+	}
+	authorIDs := []bson.M{}
+	if filter.Author != "" {
+		ids, err := s.authors.FindAuthorIDsByName(ctx, filter.Author)
+		if err != nil {
+			return nil, err
+		}
+		authorIDs = append(authorIDs, bson.M{"authorIds": bson.M{"$in": ids}})
+	}
+	if filter.AuthorID != "" {
+		id, err := primitive.ObjectIDFromHex(filter.AuthorID)
+		if err != nil {
+			return nil, utils.BadRequest("invalid_author", "invalid author id "+filter.AuthorID)
+		}
+		authorIDs = append(authorIDs, bson.M{"authorIds": id})
+	}
+	if len(authorIDs) > 0 {
+		query["$and"] = authorIDs
+	}
+	if filter.Year != 0 {
+		query["year"] = filter.Year
+	}
+	if filter.ISBN != "" {
+		if isbn, ok := utils.NormalizeISBN(filter.ISBN); ok {
+			query["isbn"] = isbn
+		} else {
+			query["isbn"] = filter.ISBN
+		}
+	}
+	if filter.Publisher != "" {
+		query["publisher"] = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Publisher), Options: "i"}
+	}
+	if filter.Language != "" {
+		query["language"] = normalizeLanguage(filter.Language)
+	}
+	if filter.Genre != "" {
+		query["genres"] = strings.ToLower(filter.Genre)
+	}
+	if filter.Tag != "" {
+		query["tags"] = utils.Slugify(filter.Tag)
+	}
+	return query, nil
+}
+
+func (s *BookService) GetBookByID(ctx context.Context, id string) (_ *models.Book, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetBookByID")
+	defer func() { tracing.End(span, err) }()
+
 	return s.repo.GetBookByID(ctx, id)
 }
 
-func (s *BookService) CreateBook(ctx context.Context, dto *dtos.CreateDTO) (*models.Book, error) {
+func (s *BookService) CreateBook(ctx context.Context, dto *dtos.CreateDTO) (_ *models.Book, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.CreateBook")
+	defer func() { tracing.End(span, err) }()
+
 	// Validate the DTO
 	if err := dto.Validate(); err != nil {
-		// Format validation error
-		errMsg := utils.FormatValidationError(err)
-		return nil, &utils.Error{Message: errMsg} // Return a custom error
+		return nil, utils.ValidationFailed(err)
 	}
-	book := models.Book{
-		Title:  dto.Title,
-		Author: dto.Author,
-		Year:   dto.Year,
+	authorIDs, err := s.authors.ResolveAuthorIDs(ctx, dto.AuthorIDs, dto.AuthorNames)
+	if err != nil {
+		return nil, err
 	}
-	res, err := s.repo.CreateBook(ctx, &book)
-	fmt.Println(res)
+	book := newBookFromDTO(dto, authorIDs)
+	res, err := s.repo.CreateBook(ctx, book)
 	if err != nil {
 		return nil, err
 	}
+	suggestions.Purge()
+	metrics.BooksCreated.Inc()
 	// Extract the inserted ID and set it on the book object
 	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
 		book.ID = oid
 	} else {
-		return nil, &utils.Error{Message: "Failed to retrieve inserted ID"}
+		return nil, errors.New("failed to retrieve inserted ID")
 	}
 
-	return &book, nil
+	return book, nil
 
 }
 
-func (s *BookService) UpdateBook(ctx context.Context, id string, dto *dtos.UpdateDTO) (*models.Book, error) {
+// newBookFromDTO builds a book from an already validated CreateDTO and its resolved
+// authors, normalizing the ISBN, language and genres. The isbn tag has already
+// checked that the ISBN normalizes.
+func newBookFromDTO(dto *dtos.CreateDTO, authorIDs []primitive.ObjectID) *models.Book {
+	now := time.Now().UTC()
+	book := &models.Book{
+		Title:       dto.Title,
+		TitleFolded: utils.FoldText(dto.Title),
+		AuthorIDs:   authorIDs,
+		Year:        dto.Year,
+		Publisher:   strings.TrimSpace(dto.Publisher),
+		Pages:       dto.Pages,
+		Genres:      normalizeGenres(dto.Genres),
+		Description: dto.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if dto.ISBN != "" {
+		book.ISBN, _ = utils.NormalizeISBN(dto.ISBN)
+	}
+	if dto.Language != "" {
+		book.Language = normalizeLanguage(dto.Language)
+	}
+
+	return book
+}
+
+// bookUpdateData collects the fields an UpdateDTO actually sets, normalized the same
+// way as on create. Given author IDs or names replace the book's authors.
+func (s *BookService) bookUpdateData(ctx context.Context, dto *dtos.UpdateDTO) (map[string]interface{}, error) {
 	updateData := map[string]interface{}{}
 	if dto.Title != "" {
 		updateData["title"] = dto.Title
+		updateData["titleFolded"] = utils.FoldText(dto.Title)
+	}
+	if len(dto.AuthorIDs) > 0 || len(dto.AuthorNames) > 0 {
+		authorIDs, err := s.authors.ResolveAuthorIDs(ctx, dto.AuthorIDs, dto.AuthorNames)
+		if err != nil {
+			return nil, err
+		}
+		updateData["authorIds"] = authorIDs
 	}
-	if dto.Author != "" {
-		updateData["author"] = dto.Author
+	if dto.ISBN != "" {
+		updateData["isbn"], _ = utils.NormalizeISBN(dto.ISBN)
 	}
-	if dto.Year != "" {
+	if dto.Year != 0 {
 		updateData["year"] = dto.Year
 	}
+	if dto.Publisher != "" {
+		updateData["publisher"] = strings.TrimSpace(dto.Publisher)
+	}
+	if dto.Language != "" {
+		updateData["language"] = normalizeLanguage(dto.Language)
+	}
+	if dto.Pages != 0 {
+		updateData["pages"] = dto.Pages
+	}
+	if dto.Genres != nil {
+		updateData["genres"] = normalizeGenres(dto.Genres)
+	}
+	if dto.Description != "" {
+		updateData["description"] = dto.Description
+	}
+	if len(updateData) > 0 {
+		updateData["updatedAt"] = time.Now().UTC()
+	}
+	return updateData, nil
+}
+
+// normalizeLanguage returns the canonical form of a BCP 47 tag, e.g. "EN-us" becomes "en-US".
+func normalizeLanguage(tag string) string {
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return tag
+	}
+	return parsed.String()
+}
+
+// normalizeGenres lowercases and trims genres and drops duplicates, keeping order.
+func normalizeGenres(genres []string) []string {
+	if genres == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	out := make([]string, 0, len(genres))
+	for _, g := range genres {
+		g = strings.ToLower(strings.TrimSpace(g))
+		if g == "" || seen[g] {
+			continue
+		}
+		seen[g] = true
+		out = append(out, g)
+	}
+	return out
+}
+
+func (s *BookService) UpdateBook(ctx context.Context, id string, dto *dtos.UpdateDTO) (_ *models.Book, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.UpdateBook")
+	defer func() { tracing.End(span, err) }()
+
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+	updateData, err := s.bookUpdateData(ctx, dto)
+	if err != nil {
+		return nil, err
+	}
+	if len(updateData) == 0 {
+		return nil, utils.Validation("no fields to update")
+	}
 
-	_, err := s.repo.UpdateBook(ctx, id, updateData)
+	res, err := s.repo.UpdateBook(ctx, id, updateData)
 	if err != nil {
 		return nil, err
 	}
+	if res.MatchedCount == 0 {
+		// Missing or merged away.
+		return nil, mongo.ErrNoDocuments
+	}
+	suggestions.Purge()
 
 	return s.repo.GetBookByID(ctx, id)
 }
 
-func (s *BookService) DeleteBook(ctx context.Context, id string) (*mongo.DeleteResult , error) {
+func (s *BookService) DeleteBook(ctx context.Context, id string) (_ *mongo.DeleteResult, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.DeleteBook")
+	defer func() { tracing.End(span, err) }()
+
+	result, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return s.deleteUnreferencedBook(sessCtx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := result.(*mongo.DeleteResult)
+	if res.DeletedCount > 0 {
+		suggestions.Purge()
+		metrics.BooksDeleted.Inc()
+		// The book is gone either way, so a leftover cover is only logged.
+		bookID, _ := primitive.ObjectIDFromHex(id)
+		if err := s.deleteCoverBlobs(ctx, bookID); err != nil {
+			logger.WarnContext(ctx, "deleting book cover", "book_id", id, logging.Err(err))
+		}
+	}
+	return res, nil
+}
+
+// deleteUnreferencedBook deletes a book unless something still refers to it, and
+// reports a book that is missing or merged away as not found. Run it inside a
+// transaction: the check and the delete then share it, so a copy or review added in
+// between makes one of them retry, since both bump the book's counters.
+func (s *BookService) deleteUnreferencedBook(ctx context.Context, id string) (*mongo.DeleteResult, error) {
+	if err := s.checkDeletable(ctx, id); err != nil {
+		return nil, err
+	}
 	res, err := s.repo.DeleteBook(ctx, id)
-	return res,err
+	if err == nil && res.DeletedCount == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return res, err
+}
+
+// checkDeletable refuses to delete a book that copies, active loans, open holds or
+// reviews still refer to. Such a book can be merged into another one instead.
+func (s *BookService) checkDeletable(ctx context.Context, id string) error {
+	bookID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	refs, err := s.merges.GetBookReferences(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	if len(refs) > 0 {
+		return utils.Conflict("book_in_use", "book still has "+strings.Join(refs, ", ")+"; merge it into another book instead")
+	}
+	return nil
 }
 
 
@@ -0,0 +1,107 @@
+package bookService
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-app/src/books/dtos"
+	"fiber-app/src/tracing"
+	"fiber-app/src/utils"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 20
+	suggestCacheSize    = 2048
+	// suggestCacheTTL bounds how stale a cached answer can get. Book writes through
+	// this process clear the cache at once; author edits and other instances' writes
+	// only show up once their entries expire.
+	suggestCacheTTL = 30 * time.Second
+)
+
+// suggestions is shared by every BookService so any of them can clear it.
+var suggestions = utils.NewLRU[string, []dtos.Suggestion](suggestCacheSize, suggestCacheTTL)
+
+// Suggest completes the search box text q with book titles and author names that
+// start with it, ignoring case and diacritics. Exact matches come first, then
+// shorter completions, then alphabetical order; a title shared by several books is
+// suggested once.
+func (s *BookService) Suggest(ctx context.Context, query *dtos.SuggestQuery) (_ []dtos.Suggestion, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.Suggest")
+	defer func() { tracing.End(span, err) }()
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSuggestLimit
+	}
+	if limit > maxSuggestLimit {
+		limit = maxSuggestLimit
+	}
+
+	prefix := utils.FoldText(query.Q)
+	if prefix == "" {
+		return []dtos.Suggestion{}, nil
+	}
+	key := strconv.Itoa(limit) + ":" + prefix
+	if cached, ok := suggestions.Get(key); ok {
+		return cached, nil
+	}
+
+	// Fetch extra of each so duplicates do not leave the list short.
+	fetch := int64(limit * 2)
+	books, err := s.repo.FindBooksByTitlePrefix(ctx, prefix, fetch)
+	if err != nil {
+		return nil, err
+	}
+	authors, err := s.authors.FindAuthorsByPrefix(ctx, prefix, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		dtos.Suggestion
+		folded string
+	}
+	candidates := make([]candidate, 0, len(books)+len(authors))
+	seen := map[string]bool{}
+	add := func(kind, text, folded, id string) {
+		if seen[kind+":"+folded] {
+			return
+		}
+		seen[kind+":"+folded] = true
+		candidates = append(candidates, candidate{Suggestion: dtos.Suggestion{Type: kind, Text: text, ID: id}, folded: folded})
+	}
+	for _, book := range books {
+		add(dtos.SuggestionTitle, book.Title, book.TitleFolded, book.ID.Hex())
+	}
+	for _, author := range authors {
+		add(dtos.SuggestionAuthor, author.Name, author.NameFolded, author.ID.Hex())
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if exactA, exactB := a.folded == prefix, b.folded == prefix; exactA != exactB {
+			return exactA
+		}
+		if len(a.folded) != len(b.folded) {
+			return len(a.folded) < len(b.folded)
+		}
+		if c := strings.Compare(a.folded, b.folded); c != 0 {
+			return c < 0
+		}
+		return a.Type < b.Type
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	result := make([]dtos.Suggestion, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.Suggestion
+	}
+	suggestions.Add(key, result)
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// operationTimeout bounds every CommonRepository call that runs a single
+	// statement. InitDB sets it from the config; zero leaves calls bounded by their
+	// caller's context alone.
+	operationTimeout time.Duration
+	// scanTimeout bounds calls that read a whole result set into memory, such as
+	// FindAll and Aggregate, which can take far longer than one statement.
+	scanTimeout time.Duration
+)
+
+// call is a CommonRepository operation in flight: its span and the deadline it
+// runs under.
+type call struct {
+	span   trace.Span
+	cancel context.CancelFunc
+}
+
+// begin starts a call of operation on r's collection. The returned context is
+// bounded by the operation timeout on top of any deadline ctx already has, such as
+// the one of the request being served, whichever comes first.
+func (r *CommonRepository) begin(ctx context.Context, operation string, filter interface{}) (context.Context, *call) {
+	return r.beginWithin(ctx, operation, filter, operationTimeout)
+}
+
+// beginScan starts a call that reads every result of a query, bounded by the scan
+// timeout instead of the operation timeout.
+func (r *CommonRepository) beginScan(ctx context.Context, operation string, filter interface{}) (context.Context, *call) {
+	return r.beginWithin(ctx, operation, filter, scanTimeout)
+}
+
+// beginCursor starts a call that returns a cursor or change stream. Those are read
+// after the call returns, under the contexts the caller passes to Next, so no
+// timeout applies. Transactions start this way too: each statement inside one
+// gets the operation timeout of its own.
+func (r *CommonRepository) beginCursor(ctx context.Context, operation string, filter interface{}) (context.Context, *call) {
+	return r.beginWithin(ctx, operation, filter, 0)
+}
+
+func (r *CommonRepository) beginWithin(ctx context.Context, operation string, filter interface{}, timeout time.Duration) (context.Context, *call) {
+	ctx, span := r.startSpan(ctx, operation, filter)
+	c := &call{span: span, cancel: func() {}}
+	if timeout > 0 {
+		ctx, c.cancel = context.WithTimeout(ctx, timeout)
+	}
+	return ctx, c
+}
+
+// end releases the call's deadline and ends its span with err.
+func (c *call) end(err error) {
+	c.cancel()
+	endSpan(c.span, err)
+}
@@ -3,10 +3,23 @@ package common
 import (
 	"context"
 	"errors"
-	"os"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
 
+	"fiber-app/src/config"
+	"fiber-app/src/logging"
+	"fiber-app/src/metrics"
+
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
 var db *mongo.Database
@@ -15,21 +28,150 @@ func GetDBCollection(col string) *mongo.Collection {
 	return db.Collection(col)
 }
 
-func InitDB() error {
-	uri := os.Getenv("MONGODB_URI")
-	if uri == "" {
-		return errors.New("you must set your 'MONGODB_URI' environmental variable. See\n\t https://www.mongodb.com/docs/drivers/go/current/usage-examples/#environment-variable")
+// GetGridFSBucket returns a GridFS bucket with the given name. Buckets are not safe
+// for concurrent use, so callers should get a fresh one per operation.
+func GetGridFSBucket(name string) (*gridfs.Bucket, error) {
+	return gridfs.NewBucket(db, options.GridFSBucket().SetName(name))
+}
+
+// maxConnectBackoff caps the wait between startup pings.
+const maxConnectBackoff = 30 * time.Second
+
+var logger = logging.For("mongo")
+
+// InitDB connects to the MongoDB deployment and database cfg names, and pings the
+// primary until it answers, retrying with exponential backoff, so the app does not
+// start serving against a database it cannot reach. It also refuses a standalone
+// server, since circulation writes run in transactions.
+func InitDB(cfg config.MongoConfig) error {
+	opts, err := clientOptions(cfg)
+	if err != nil {
+		return err
 	}
-	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
+	client, err := mongo.Connect(context.Background(), opts)
 	if err != nil {
 		return err
 	}
 
-	db = client.Database("go_demo")
+	if err := pingWithRetry(client, cfg); err != nil {
+		_ = client.Disconnect(context.Background())
+		return err
+	}
+	if err := requireTransactions(client, pingTimeout(cfg.ServerSelectionTimeout)); err != nil {
+		_ = client.Disconnect(context.Background())
+		return err
+	}
+
+	db = client.Database(cfg.Database)
+	operationTimeout = cfg.OperationTimeout
+	scanTimeout = cfg.ScanTimeout
 
 	return nil
 }
 
+// clientOptions applies cfg's pool, timeout, concern and read preference settings
+// over the options in its URI.
+func clientOptions(cfg config.MongoConfig) (*options.ClientOptions, error) {
+	opts := options.Client().
+		ApplyURI(cfg.URI.Value()).
+		SetMonitor(commandMonitors(metrics.CommandMonitor(), otelmongo.NewMonitor())).
+		SetPoolMonitor(metrics.PoolMonitor()).
+		SetMaxPoolSize(cfg.MaxPoolSize).
+		SetMinPoolSize(cfg.MinPoolSize)
+	if cfg.MaxConnIdleTime > 0 {
+		opts.SetMaxConnIdleTime(cfg.MaxConnIdleTime)
+	}
+	if cfg.ConnectTimeout > 0 {
+		opts.SetConnectTimeout(cfg.ConnectTimeout)
+	}
+	if cfg.ServerSelectionTimeout > 0 {
+		opts.SetServerSelectionTimeout(cfg.ServerSelectionTimeout)
+	}
+	if cfg.SocketTimeout > 0 {
+		opts.SetSocketTimeout(cfg.SocketTimeout)
+	}
+	if cfg.ReadConcern != "" {
+		opts.SetReadConcern(&readconcern.ReadConcern{Level: cfg.ReadConcern})
+	}
+	if cfg.WriteConcern != "" {
+		var w interface{} = cfg.WriteConcern
+		if n, err := strconv.Atoi(cfg.WriteConcern); err == nil {
+			w = n
+		}
+		opts.SetWriteConcern(&writeconcern.WriteConcern{W: w})
+	}
+	if cfg.ReadPreference != "" {
+		mode, err := readpref.ModeFromString(cfg.ReadPreference)
+		if err != nil {
+			return nil, err
+		}
+		pref, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetReadPreference(pref)
+	}
+	return opts, opts.Validate()
+}
+
+// pingWithRetry pings the primary up to cfg.ConnectRetries more times after the
+// first failure, doubling the wait between attempts from cfg.ConnectBackoff.
+func pingWithRetry(client *mongo.Client, cfg config.MongoConfig) error {
+	backoff := cfg.ConnectBackoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), pingTimeout(cfg.ServerSelectionTimeout))
+		err := client.Ping(ctx, readpref.Primary())
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt >= cfg.ConnectRetries {
+			return fmt.Errorf("mongodb unreachable after %d attempts: %w", attempt+1, err)
+		}
+
+		// Jitter keeps instances started together from retrying in lockstep.
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		logger.Warn("pinging mongodb, retrying", "attempt", attempt+1, "retry_in", wait.String(), logging.Err(err))
+		time.Sleep(wait)
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+// pingTimeout bounds one startup ping by the server selection timeout, or the
+// driver's default of 30s when it is not set.
+func pingTimeout(serverSelection time.Duration) time.Duration {
+	if serverSelection <= 0 {
+		return 30 * time.Second
+	}
+	return serverSelection
+}
+
+// requireTransactions fails unless the deployment is a replica set or a sharded
+// cluster. Loans, holds, copies and merges write in transactions, which a standalone
+// server rejects on the first write, so it is better caught at startup.
+func requireTransactions(client *mongo.Client, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return fmt.Errorf("checking mongodb topology: %w", err)
+	}
+	if hello.SetName == "" && hello.Msg != "isdbgrid" {
+		return errors.New("mongodb is a standalone server but transactions need a replica set: " +
+			"start mongod with --replSet rs0, run rs.initiate() once and add ?replicaSet=rs0 to MONGODB_URI")
+	}
+	return nil
+}
+
+// PingDB checks that the primary is reachable.
+func PingDB(ctx context.Context) error {
+	return db.Client().Ping(ctx, readpref.Primary())
+}
+
 func CloseDB() error {
 	return db.Client().Disconnect(context.Background())
 }
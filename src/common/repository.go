@@ -2,12 +2,14 @@ package common
 
 import (
 	"context"
-	"fmt"
+
+	"fiber-app/src/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type CommonRepository struct {
@@ -19,7 +21,10 @@ func NewCommonRepository(collection *mongo.Collection) *CommonRepository {
 }
 
 // FindAll retrieves all documents in the collection with optional filter and sorting.
-func (r *CommonRepository) FindAll(ctx context.Context, filter interface{}, result interface{}, opts ...*options.FindOptions) error {
+func (r *CommonRepository) FindAll(ctx context.Context, filter interface{}, result interface{}, opts ...*options.FindOptions) (err error) {
+	ctx, call := r.beginScan(ctx, "find", filter)
+	defer func() { call.end(err) }()
+
 	cursor, err := r.Collection.Find(ctx, filter, opts...)
 	if err != nil {
 		return err
@@ -29,66 +34,127 @@ func (r *CommonRepository) FindAll(ctx context.Context, filter interface{}, resu
 	return cursor.All(ctx, result)
 }
 
+// FindCursor returns a cursor over the matching documents so callers can iterate
+// large result sets without loading them into memory. The caller must close it.
+func (r *CommonRepository) FindCursor(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	ctx, call := r.beginCursor(ctx, "find", filter)
+	cursor, err := r.Collection.Find(ctx, filter, opts...)
+	call.end(err)
+	return cursor, err
+}
+
 // FindOne retrieves a single document by a filter.
 func (r *CommonRepository) FindOne(ctx context.Context, filter interface{}, result interface{}) error {
-	fmt.Println(filter,result)
-	return r.Collection.FindOne(ctx, filter).Decode(result)
+	ctx, call := r.begin(ctx, "findOne", filter)
+	err := r.Collection.FindOne(ctx, filter).Decode(result)
+	call.end(err)
+	return err
 }
 
 // InsertOne inserts a document into the collection.
 func (r *CommonRepository) InsertOne(ctx context.Context, document interface{}) (*mongo.InsertOneResult, error) {
-	return r.Collection.InsertOne(ctx, document)
+	ctx, call := r.begin(ctx, "insertOne", nil)
+	res, err := r.Collection.InsertOne(ctx, document)
+	call.end(err)
+	return res, err
 }
 
 // InsertMany inserts multiple documents into the collection.
 func (r *CommonRepository) InsertMany(ctx context.Context, documents []interface{}) (*mongo.InsertManyResult, error) {
-	return r.Collection.InsertMany(ctx, documents)
+	ctx, call := r.begin(ctx, "insertMany", nil)
+	res, err := r.Collection.InsertMany(ctx, documents)
+	call.end(err)
+	return res, err
 }
 // BatchInsert inserts multiple documents into the collection.
 func (r *CommonRepository) BatchInsert(ctx context.Context, documents []interface{}) (*mongo.InsertManyResult, error) {
-    return r.Collection.InsertMany(ctx, documents)
+	return r.InsertMany(ctx, documents)
 }
 
 // UpdateOne updates a document by a filter.
 func (r *CommonRepository) UpdateOne(ctx context.Context, filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
-	return r.Collection.UpdateOne(ctx, filter, bson.M{"$set": update})
+	ctx, call := r.begin(ctx, "updateOne", filter)
+	res, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": update})
+	call.end(err)
+	return res, err
 }
 
 // UpdateMany updates multiple documents by a filter.
 func (r *CommonRepository) UpdateMany(ctx context.Context, filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
-	return r.Collection.UpdateMany(ctx, filter, bson.M{"$set": update})
+	ctx, call := r.begin(ctx, "updateMany", filter)
+	res, err := r.Collection.UpdateMany(ctx, filter, bson.M{"$set": update})
+	call.end(err)
+	return res, err
+}
+// UpdateOneRaw updates a document by a filter with update as given: an update
+// document with its own operators, or an aggregation pipeline. UpdateOne only $sets.
+func (r *CommonRepository) UpdateOneRaw(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, call := r.begin(ctx, "updateOne", filter)
+	res, err := r.Collection.UpdateOne(ctx, filter, update, opts...)
+	call.end(err)
+	return res, err
 }
+
+// UpdateManyRaw is UpdateOneRaw for every document matching filter.
+func (r *CommonRepository) UpdateManyRaw(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, call := r.begin(ctx, "updateMany", filter)
+	res, err := r.Collection.UpdateMany(ctx, filter, update, opts...)
+	call.end(err)
+	return res, err
+}
+
 // Upsert updates or inserts a document.
 func (r *CommonRepository) Upsert(ctx context.Context, filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
-    opts := options.Update().SetUpsert(true)
-    return r.Collection.UpdateOne(ctx, filter, bson.M{"$set": update}, opts)
+	ctx, call := r.begin(ctx, "upsert", filter)
+	opts := options.Update().SetUpsert(true)
+	res, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": update}, opts)
+	call.end(err)
+	return res, err
 }
 // PushToArray pushes an element to an array field.
 func (r *CommonRepository) PushToArray(ctx context.Context, filter interface{}, field string, value interface{}) (*mongo.UpdateResult, error) {
-    return r.Collection.UpdateOne(ctx, filter, bson.M{"$push": bson.M{field: value}})
+	ctx, call := r.begin(ctx, "push", filter)
+	res, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$push": bson.M{field: value}})
+	call.end(err)
+	return res, err
 }
 // AddToSet adds a value to an array only if it doesn't already exist.
 func (r *CommonRepository) AddToSet(ctx context.Context, filter interface{}, field string, value interface{}) (*mongo.UpdateResult, error) {
-    return r.Collection.UpdateOne(ctx, filter, bson.M{"$addToSet": bson.M{field: value}})
+	ctx, call := r.begin(ctx, "addToSet", filter)
+	res, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$addToSet": bson.M{field: value}})
+	call.end(err)
+	return res, err
 }
 
 // DeleteOne deletes a document by a filter.
 func (r *CommonRepository) DeleteOne(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error) {
-	return r.Collection.DeleteOne(ctx, filter)
+	ctx, call := r.begin(ctx, "deleteOne", filter)
+	res, err := r.Collection.DeleteOne(ctx, filter)
+	call.end(err)
+	return res, err
 }
 
 // DeleteMany deletes multiple documents by a filter.
 func (r *CommonRepository) DeleteMany(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error) {
-	return r.Collection.DeleteMany(ctx, filter)
+	ctx, call := r.begin(ctx, "deleteMany", filter)
+	res, err := r.Collection.DeleteMany(ctx, filter)
+	call.end(err)
+	return res, err
 }
 
 // Count counts documents matching a filter.
 func (r *CommonRepository) Count(ctx context.Context, filter interface{}) (int64, error) {
-	return r.Collection.CountDocuments(ctx, filter)
+	ctx, call := r.begin(ctx, "count", filter)
+	count, err := r.Collection.CountDocuments(ctx, filter)
+	call.end(err)
+	return count, err
 }
 
 // Aggregate performs an aggregation pipeline and stores the results in the provided interface.
-func (r *CommonRepository) Aggregate(ctx context.Context, pipeline mongo.Pipeline, result interface{}) error {
+func (r *CommonRepository) Aggregate(ctx context.Context, pipeline mongo.Pipeline, result interface{}) (err error) {
+	ctx, call := r.beginScan(ctx, "aggregate", pipeline)
+	defer func() { call.end(err) }()
+
 	cursor, err := r.Collection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return err
@@ -98,8 +164,20 @@ func (r *CommonRepository) Aggregate(ctx context.Context, pipeline mongo.Pipelin
 	return cursor.All(ctx, result)
 }
 
+// AggregateCursor runs an aggregation pipeline and returns the cursor so large
+// results can be streamed. The caller must close it.
+func (r *CommonRepository) AggregateCursor(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	ctx, call := r.beginCursor(ctx, "aggregate", pipeline)
+	cursor, err := r.Collection.Aggregate(ctx, pipeline, opts...)
+	call.end(err)
+	return cursor, err
+}
+
 // Paginate retrieves paginated results from the collection.
-func (r *CommonRepository) Paginate(ctx context.Context, filter interface{}, result interface{}, page int64, pageSize int64, sort interface{}) (int64, error) {
+func (r *CommonRepository) Paginate(ctx context.Context, filter interface{}, result interface{}, page int64, pageSize int64, sort interface{}) (_ int64, err error) {
+	ctx, call := r.begin(ctx, "paginate", filter)
+	defer func() { call.end(err) }()
+
 	// Calculate skip and limit
 	skip := (page - 1) * pageSize
 	findOptions := options.Find().SetSkip(skip).SetLimit(pageSize)
@@ -139,49 +217,101 @@ func (r *CommonRepository) Exists(ctx context.Context, filter interface{}) (bool
 	return count > 0, nil
 }
 
-// ConvertID converts a string ID to a MongoDB ObjectID.
+// ConvertID converts a string ID to a MongoDB ObjectID. Malformed IDs are reported
+// as a bad request.
 func (r *CommonRepository) ConvertID(id string) (primitive.ObjectID, error) {
-	return primitive.ObjectIDFromHex(id)
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, utils.BadRequest("invalid_id", "invalid id "+id)
+	}
+	return objectID, nil
 }
 // Distinct retrieves distinct values for a specified field.
 func (r *CommonRepository) Distinct(ctx context.Context, field string, filter interface{}) ([]interface{}, error) {
-    return r.Collection.Distinct(ctx, field, filter)
+	ctx, call := r.begin(ctx, "distinct", filter)
+	values, err := r.Collection.Distinct(ctx, field, filter)
+	call.end(err)
+	return values, err
 }
 // BulkWrite performs multiple write operations in a single batch.
 func (r *CommonRepository) BulkWrite(ctx context.Context, operations []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
-    return r.Collection.BulkWrite(ctx, operations, opts...)
+	ctx, call := r.begin(ctx, "bulkWrite", nil)
+	call.span.SetAttributes(attribute.Int("db.operation.batch.size", len(operations)))
+	res, err := r.Collection.BulkWrite(ctx, operations, opts...)
+	call.end(err)
+	return res, err
 }
 // FindAndModify atomically finds and modifies a document.
 func (r *CommonRepository) FindAndModify(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) (*mongo.SingleResult, error) {
-    return r.Collection.FindOneAndUpdate(ctx, filter, update, opts...), nil
+	ctx, call := r.begin(ctx, "findOneAndUpdate", filter)
+	res := r.Collection.FindOneAndUpdate(ctx, filter, update, opts...)
+	call.end(res.Err())
+	return res, nil
+}
+
+// WithTransaction runs fn inside a multi-document transaction, committing when it
+// returns nil and aborting otherwise. Transactions require a replica set or sharded cluster.
+// The statements fn runs are bounded one by one, not the transaction as a whole,
+// which the server limits to a minute anyway.
+func (r *CommonRepository) WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error) {
+	ctx, call := r.beginCursor(ctx, "transaction", nil)
+	session, err := r.Collection.Database().Client().StartSession()
+	if err != nil {
+		call.end(err)
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	result, err := session.WithTransaction(ctx, fn)
+	call.end(err)
+	return result, err
 }
 
 // Watch listens to changes on the collection and streams them.
 func (r *CommonRepository) Watch(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
-    return r.Collection.Watch(ctx, pipeline, opts...)
+	ctx, call := r.beginCursor(ctx, "watch", pipeline)
+	stream, err := r.Collection.Watch(ctx, pipeline, opts...)
+	call.end(err)
+	return stream, err
 }
 // IncrementField increments a numeric field atomically.
 func (r *CommonRepository) IncrementField(ctx context.Context, filter interface{}, field string, incrementValue int) (*mongo.UpdateResult, error) {
-    return r.Collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{field: incrementValue}})
+	ctx, call := r.begin(ctx, "increment", filter)
+	res, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{field: incrementValue}})
+	call.end(err)
+	return res, err
 }
 // CountDocuments counts the number of documents matching the filter.
 func (r *CommonRepository) CountDocuments(ctx context.Context, filter interface{}) (int64, error) {
-    return r.Collection.CountDocuments(ctx, filter)
+	return r.Count(ctx, filter)
 }
 // EstimatedDocumentCount provides an estimated count of documents in the collection.
 func (r *CommonRepository) EstimatedDocumentCount(ctx context.Context) (int64, error) {
-    return r.Collection.EstimatedDocumentCount(ctx)
+	ctx, call := r.begin(ctx, "estimatedCount", nil)
+	count, err := r.Collection.EstimatedDocumentCount(ctx)
+	call.end(err)
+	return count, err
 }
 // FindAndDelete finds and deletes a document, returning the deleted document.
 func (r *CommonRepository) FindAndDelete(ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) (*mongo.SingleResult, error) {
-    return r.Collection.FindOneAndDelete(ctx, filter, opts...), nil
+	ctx, call := r.begin(ctx, "findOneAndDelete", filter)
+	res := r.Collection.FindOneAndDelete(ctx, filter, opts...)
+	call.end(res.Err())
+	return res, nil
 }
 // ReplaceDocument replaces a document with a new one.
 func (r *CommonRepository) ReplaceDocument(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
-    return r.Collection.ReplaceOne(ctx, filter, replacement, opts...)
+	ctx, call := r.begin(ctx, "replaceOne", filter)
+	res, err := r.Collection.ReplaceOne(ctx, filter, replacement, opts...)
+	call.end(err)
+	return res, err
 }
 // TextSearch performs a text search query on indexed fields.
 func (r *CommonRepository) TextSearch(ctx context.Context, query string, opts ...*options.FindOptions) (*mongo.Cursor, error) {
-    return r.Collection.Find(ctx, bson.M{"$text": bson.M{"$search": query}}, opts...)
+	filter := bson.M{"$text": bson.M{"$search": query}}
+	ctx, call := r.beginCursor(ctx, "find", filter)
+	cursor, err := r.Collection.Find(ctx, filter, opts...)
+	call.end(err)
+	return cursor, err
 }
 
@@ -0,0 +1,123 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"fiber-app/src/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxQueryShape caps the db.query.shape attribute of a span.
+const maxQueryShape = 512
+
+// startSpan starts a span for a repository operation on r's collection. The filter
+// is recorded by shape only, with every value replaced by ?, so spans do not carry
+// user data.
+func (r *CommonRepository) startSpan(ctx context.Context, operation string, filter interface{}) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemMongoDB,
+		semconv.DBCollectionName(r.Collection.Name()),
+		semconv.DBOperationName(operation),
+	}
+	if filter != nil {
+		attrs = append(attrs, attribute.String("db.query.shape", QueryShape(filter)))
+	}
+	return tracing.Start(ctx, "CommonRepository."+operation+" "+r.Collection.Name(), attrs...)
+}
+
+// endSpan ends a repository span. A missing document is an answer, not a failure.
+func endSpan(span trace.Span, err error) {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
+
+// QueryShape renders a filter or pipeline with its values replaced by ?, e.g.
+// {"_id": {"$in": [?]}, "deletedAt": {"$exists": ?}}.
+func QueryShape(filter interface{}) string {
+	t, data, err := bson.MarshalValue(filter)
+	if err != nil {
+		return "?"
+	}
+	var b strings.Builder
+	writeShape(&b, bson.RawValue{Type: t, Value: data})
+	shape := b.String()
+	if len(shape) > maxQueryShape {
+		shape = shape[:maxQueryShape] + "..."
+	}
+	return shape
+}
+
+func writeShape(b *strings.Builder, value bson.RawValue) {
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		elements, _ := value.Document().Elements()
+		b.WriteByte('{')
+		for i, element := range elements {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(`"` + element.Key() + `": `)
+			writeShape(b, element.Value())
+		}
+		b.WriteByte('}')
+	case bsontype.Array:
+		// Values collapse to a single ?; documents, such as pipeline stages, are kept.
+		values, _ := value.Array().Values()
+		var parts []string
+		scalar := false
+		for _, v := range values {
+			if v.Type != bsontype.EmbeddedDocument && v.Type != bsontype.Array {
+				scalar = true
+				continue
+			}
+			var part strings.Builder
+			writeShape(&part, v)
+			parts = append(parts, part.String())
+		}
+		if scalar {
+			parts = append(parts, "?")
+		}
+		b.WriteString("[" + strings.Join(parts, ", ") + "]")
+	default:
+		b.WriteByte('?')
+	}
+}
+
+// commandMonitors fans driver command events out to several monitors, since the
+// client takes only one.
+func commandMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
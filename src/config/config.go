@@ -0,0 +1,297 @@
+// Package config loads the app's settings into a typed Config.
+//
+// Every setting has an environment variable and a key in the optional YAML or TOML
+// config file. A value is taken from, in order of precedence:
+//
+//  1. the process environment
+//  2. the .env file in the working directory, unless PROD is "true"
+//  3. the config file, when one is given
+//  4. the setting's default
+//
+// The OpenTelemetry SDK reads its standard OTEL_* variables itself, so they are not
+// part of Config.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"fiber-app/src/utils"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-playground/validator/v10"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every setting of the app.
+type Config struct {
+	Server ServerConfig `yaml:"server" toml:"server"`
+	Mongo  MongoConfig  `yaml:"mongo" toml:"mongo"`
+	Jobs   JobsConfig   `yaml:"jobs" toml:"jobs"`
+	Blob   BlobConfig   `yaml:"blob" toml:"blob"`
+	Fines  FinesConfig  `yaml:"fines" toml:"fines"`
+	Log    LogConfig    `yaml:"log" toml:"log"`
+
+	// sources records where each setting came from, by environment variable.
+	sources map[string]string
+}
+
+// ServerConfig holds the HTTP server settings.
+type ServerConfig struct {
+	Port int `yaml:"port" toml:"port" env:"PORT" default:"8080" validate:"min=1,max=65535"`
+	// ShutdownTimeout bounds the drain of requests, jobs and tasks on shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" toml:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT" default:"30s" validate:"gt=0"`
+	// ShutdownDelay is how long to keep serving after a shutdown signal, while
+	// readiness fails, so load balancers stop routing here first.
+	ShutdownDelay time.Duration `yaml:"shutdownDelay" toml:"shutdownDelay" env:"SHUTDOWN_DELAY" default:"0s" validate:"gte=0"`
+}
+
+// MongoConfig holds the database settings. The pool, timeout, concern and read
+// preference settings override the same options given in URI; a zero timeout or an
+// empty concern leaves the driver's default or the URI's.
+type MongoConfig struct {
+	URI      Secret `yaml:"uri" toml:"uri" env:"MONGODB_URI" validate:"required,startswith=mongodb"`
+	Database string `yaml:"database" toml:"database" env:"MONGODB_DATABASE" default:"go_demo" validate:"required"`
+
+	MaxPoolSize     uint64        `yaml:"maxPoolSize" toml:"maxPoolSize" env:"MONGODB_MAX_POOL_SIZE" default:"100" validate:"gtefield=MinPoolSize"`
+	MinPoolSize     uint64        `yaml:"minPoolSize" toml:"minPoolSize" env:"MONGODB_MIN_POOL_SIZE" default:"0"`
+	MaxConnIdleTime time.Duration `yaml:"maxConnIdleTime" toml:"maxConnIdleTime" env:"MONGODB_MAX_CONN_IDLE_TIME" default:"0s" validate:"gte=0"`
+
+	ConnectTimeout         time.Duration `yaml:"connectTimeout" toml:"connectTimeout" env:"MONGODB_CONNECT_TIMEOUT" default:"10s" validate:"gte=0"`
+	ServerSelectionTimeout time.Duration `yaml:"serverSelectionTimeout" toml:"serverSelectionTimeout" env:"MONGODB_SERVER_SELECTION_TIMEOUT" default:"10s" validate:"gte=0"`
+	SocketTimeout          time.Duration `yaml:"socketTimeout" toml:"socketTimeout" env:"MONGODB_SOCKET_TIMEOUT" default:"0s" validate:"gte=0"`
+	// OperationTimeout bounds every single-statement repository call, each statement
+	// of a transaction included, on top of any deadline of the request it serves.
+	// Zero means calls are bounded by the request alone.
+	OperationTimeout time.Duration `yaml:"operationTimeout" toml:"operationTimeout" env:"MONGODB_OPERATION_TIMEOUT" default:"15s" validate:"gte=0"`
+	// ScanTimeout bounds repository calls that read a whole result set, such as
+	// list pages with their authors, stats and the duplicate finder.
+	ScanTimeout time.Duration `yaml:"scanTimeout" toml:"scanTimeout" env:"MONGODB_SCAN_TIMEOUT" default:"2m" validate:"gte=0"`
+
+	ReadConcern    string `yaml:"readConcern" toml:"readConcern" env:"MONGODB_READ_CONCERN" validate:"omitempty,oneof=local available majority linearizable snapshot"`
+	WriteConcern   string `yaml:"writeConcern" toml:"writeConcern" env:"MONGODB_WRITE_CONCERN" validate:"omitempty,eq=majority|number"`
+	ReadPreference string `yaml:"readPreference" toml:"readPreference" env:"MONGODB_READ_PREFERENCE" validate:"omitempty,oneof=primary primaryPreferred secondary secondaryPreferred nearest"`
+
+	// ConnectRetries is how many times the startup ping is retried before giving up,
+	// waiting ConnectBackoff after the first failure and twice as long after each
+	// next one.
+	ConnectRetries int           `yaml:"connectRetries" toml:"connectRetries" env:"MONGODB_CONNECT_RETRIES" default:"5" validate:"gte=0"`
+	ConnectBackoff time.Duration `yaml:"connectBackoff" toml:"connectBackoff" env:"MONGODB_CONNECT_BACKOFF" default:"1s" validate:"gt=0"`
+}
+
+// JobsConfig holds the background job settings.
+type JobsConfig struct {
+	Workers int `yaml:"workers" toml:"workers" env:"JOB_WORKERS" default:"2" validate:"min=1,max=64"`
+}
+
+// BlobConfig picks where binary files such as cover images are kept: "gridfs" keeps
+// them in GridFS and "local" in directories under Dir.
+type BlobConfig struct {
+	Store string `yaml:"store" toml:"store" env:"BLOB_STORE" default:"gridfs" validate:"oneof=gridfs local"`
+	Dir   string `yaml:"dir" toml:"dir" env:"BLOB_DIR" default:"data/blobs" validate:"required"`
+}
+
+// FinesConfig holds the overdue fine settings. Rules is a JSON object of role to
+// rule, e.g. {"member":{"dailyRate":50,"graceDays":0,"cap":2000}}; roles it leaves
+// out keep their defaults. BlockThreshold is in cents.
+type FinesConfig struct {
+	Rules          string `yaml:"rules" toml:"rules" env:"FINE_RULES" validate:"omitempty,json"`
+	BlockThreshold int64  `yaml:"blockThreshold" toml:"blockThreshold" env:"FINE_BLOCK_THRESHOLD" default:"1000" validate:"gte=0"`
+}
+
+// LogConfig holds the log levels: Level for every module and Levels, such as
+// "jobs=debug,scheduler=warn", for those that differ.
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL" default:"info"`
+	Levels string `yaml:"levels" toml:"levels" env:"LOG_LEVELS"`
+}
+
+// Where a setting's value can come from, as reported by Source.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceDotEnv  = ".env"
+	SourceEnv     = "env"
+)
+
+var (
+	currentMu sync.RWMutex
+	current   *Config
+)
+
+// Get returns the config loaded last, or the defaults when none was loaded.
+func Get() *Config {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	if current == nil {
+		cfg, _ := defaults()
+		return cfg
+	}
+	return current
+}
+
+// Load reads the config from the sources in their order of precedence, with path
+// naming the optional config file, and validates it. It returns the config even
+// when a setting is invalid, so it can still be printed, but only a valid one is
+// kept for Get. A config file that cannot be read returns no config.
+func Load(path string) (*Config, error) {
+	cfg, err := defaults()
+	if err != nil {
+		return nil, err
+	}
+
+	if path != "" {
+		if err := cfg.decodeFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// .env never overrides the process environment, so what was set before loading
+	// it came from the environment.
+	preset := map[string]bool{}
+	for _, f := range cfg.fields() {
+		_, preset[f.env] = os.LookupEnv(f.env)
+	}
+	if os.Getenv("PROD") != "true" {
+		if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("config: .env: %w", err)
+		}
+	}
+
+	var problems []string
+	for _, f := range cfg.fields() {
+		raw, ok := os.LookupEnv(f.env)
+		if !ok {
+			continue
+		}
+		if err := setField(f.value, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", f.env, err))
+			continue
+		}
+		if preset[f.env] {
+			cfg.sources[f.env] = SourceEnv
+		} else {
+			cfg.sources[f.env] = SourceDotEnv
+		}
+	}
+	if len(problems) == 0 {
+		problems = cfg.validate()
+	}
+	if len(problems) > 0 {
+		return cfg, errors.New("config: " + strings.Join(problems, "; "))
+	}
+
+	currentMu.Lock()
+	current = cfg
+	currentMu.Unlock()
+	return cfg, nil
+}
+
+// Source returns where the setting of the environment variable env came from.
+func (c *Config) Source(env string) string {
+	if source, ok := c.sources[env]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+func defaults() (*Config, error) {
+	cfg := &Config{sources: map[string]string{}}
+	for _, f := range cfg.fields() {
+		if f.def == "" {
+			continue
+		}
+		if err := setField(f.value, f.def); err != nil {
+			return nil, fmt.Errorf("config: default of %s: %w", f.env, err)
+		}
+	}
+	return cfg, nil
+}
+
+// decodeFile reads the YAML or TOML file at path, by its extension, over c. Only
+// the keys the file sets change, and unknown keys are an error so typos show up.
+// The sources of the settings it sets are recorded by comparing with the defaults.
+func (c *Config) decodeFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	before := c.snapshot()
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config: %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config: %s: unknown key %q", path, undecoded[0].String())
+		}
+	default:
+		return fmt.Errorf("config: %s: unsupported config file type %q, want .yaml, .yml or .toml", path, ext)
+	}
+
+	for _, f := range c.fields() {
+		if format(f.value) != before[f.env] {
+			c.sources[f.env] = SourceFile
+		}
+	}
+	return nil
+}
+
+// snapshot returns the formatted value of every setting by environment variable.
+func (c *Config) snapshot() map[string]string {
+	values := map[string]string{}
+	for _, f := range c.fields() {
+		values[f.env] = format(f.value)
+	}
+	return values
+}
+
+// validate checks c against its validate tags and reports each failure by the
+// environment variable of the setting, never with its value, since it may be secret.
+func (c *Config) validate() []string {
+	err := utils.Validator().Struct(c)
+	var failures validator.ValidationErrors
+	if !errors.As(err, &failures) {
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return nil
+	}
+
+	envs := map[string]string{}
+	for _, f := range c.fields() {
+		envs[f.namespace] = f.env
+	}
+	problems := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		name := envs[failure.StructNamespace()]
+		if name == "" {
+			name = failure.StructNamespace()
+		}
+		rule := failure.Tag()
+		if failure.Param() != "" {
+			rule += "=" + failure.Param()
+		}
+		if rule == "required" {
+			problems = append(problems, name+" is required")
+		} else {
+			problems = append(problems, name+" must satisfy "+rule)
+		}
+	}
+	return problems
+}
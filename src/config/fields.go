@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// field is one setting of a Config, found by walking its structs.
+type field struct {
+	// path is the setting's key in config files, such as ["server", "port"].
+	path []string
+	// env is the setting's environment variable.
+	env string
+	// def is the setting's default as the environment variable would spell it.
+	def string
+	// namespace is the Go path validator reports failures with, e.g. Config.Server.Port.
+	namespace string
+	value     reflect.Value
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// fields lists the settings of c in declaration order. Their values can be set.
+func (c *Config) fields() []field {
+	var out []field
+	walk(reflect.ValueOf(c).Elem(), nil, "Config", &out)
+	return out
+}
+
+func walk(v reflect.Value, path []string, namespace string, out *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key := append(append([]string(nil), path...), sf.Tag.Get("yaml"))
+		ns := namespace + "." + sf.Name
+		if sf.Type.Kind() == reflect.Struct && sf.Type != durationType {
+			walk(v.Field(i), key, ns, out)
+			continue
+		}
+		*out = append(*out, field{
+			path:      key,
+			env:       sf.Tag.Get("env"),
+			def:       sf.Tag.Get("default"),
+			namespace: ns,
+			value:     v.Field(i),
+		})
+	}
+}
+
+// setField parses raw, spelled as in an environment variable, into v.
+func setField(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid non-negative integer %q", raw)
+		}
+		v.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// format spells v as an environment variable would, revealing secrets.
+func format(v reflect.Value) string {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats Print can write.
+const (
+	FormatYAML = "yaml"
+	FormatEnv  = "env"
+)
+
+// Print writes every setting of cfg with where it came from, secrets masked. YAML
+// output is a valid config file; env output lists the environment variables.
+func Print(w io.Writer, cfg *Config, format string) error {
+	switch format {
+	case FormatYAML:
+		return printYAML(w, cfg)
+	case FormatEnv:
+		for _, f := range cfg.fields() {
+			if _, err := fmt.Fprintf(w, "%s=%s # %s\n", f.env, display(f), cfg.Source(f.env)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("config: unknown print format %q, want %s or %s", format, FormatYAML, FormatEnv)
+	}
+}
+
+func printYAML(w io.Writer, cfg *Config) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sections := map[string]*yaml.Node{}
+	for _, f := range cfg.fields() {
+		section, ok := sections[f.path[0]]
+		if !ok {
+			section = &yaml.Node{Kind: yaml.MappingNode}
+			sections[f.path[0]] = section
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.path[0]}, section)
+		}
+
+		// Durations and secrets are printed as strings, like config files spell them.
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: display(f)}
+		if f.value.Kind() != reflect.String && f.value.Type() != durationType {
+			if err := value.Encode(f.value.Interface()); err != nil {
+				return err
+			}
+		}
+		value.LineComment = cfg.Source(f.env) + ", " + f.env
+		section.Content = append(section.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.path[1]}, value)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// display spells the value of f for output, masking secrets.
+func display(f field) string {
+	if secret, ok := f.value.Interface().(Secret); ok {
+		return secret.Masked()
+	}
+	return format(f.value)
+}
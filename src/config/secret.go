@@ -0,0 +1,53 @@
+package config
+
+import (
+	"log/slog"
+	"net/url"
+)
+
+// masked replaces secrets that are not URLs when printed.
+const masked = "****"
+
+// Secret is a setting such as a connection string that must not leak into logs or
+// output. Printing, logging or marshalling it shows a masked form; Value returns
+// the real one.
+type Secret string
+
+// Value returns the secret unmasked.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// Masked returns s with its password and query masked when it is a URL, and
+// entirely masked otherwise. An empty secret stays empty, so it can be told apart
+// from a set one.
+func (s Secret) Masked() string {
+	if s == "" {
+		return ""
+	}
+	u, err := url.Parse(string(s))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return masked
+	}
+	// Query parameters can carry credentials too, such as a TLS key password.
+	if u.RawQuery != "" {
+		u.RawQuery = masked
+	}
+	return u.Redacted()
+}
+
+func (s Secret) String() string {
+	return s.Masked()
+}
+
+func (s Secret) GoString() string {
+	return `"` + s.Masked() + `"`
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.Masked()), nil
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.Masked())
+}
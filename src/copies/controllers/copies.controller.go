@@ -0,0 +1,76 @@
+package copiesController
+
+import (
+	"fiber-app/src/copies/dtos"
+	copyService "fiber-app/src/copies/services"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CopyController struct {
+	copyService *copyService.CopyService
+}
+
+func NewCopyController() *CopyController {
+	return &CopyController{
+		copyService: copyService.NewCopyService(),
+	}
+}
+
+// GetBookCopies lists the copies of the book in the :id param.
+func (cc *CopyController) GetBookCopies(c *fiber.Ctx) error {
+	filter := new(dtos.CopyFilter)
+	if err := utils.ParseQuery(c, filter); err != nil {
+		return err
+	}
+
+	copies, err := cc.copyService.GetCopiesByBook(c.UserContext(), c.Params("id"), filter)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": copies})
+}
+
+func (cc *CopyController) GetCopy(c *fiber.Ctx) error {
+	copy, err := cc.copyService.GetCopyByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": copy})
+}
+
+// CreateBookCopy adds a copy to the book in the :id param.
+func (cc *CopyController) CreateBookCopy(c *fiber.Ctx) error {
+	dto := new(dtos.CreateDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	result, err := cc.copyService.CreateCopy(c.UserContext(), c.Params("id"), dto)
+	if err != nil {
+		return err
+	}
+	return c.Status(201).JSON(fiber.Map{"result": result})
+}
+
+func (cc *CopyController) UpdateCopy(c *fiber.Ctx) error {
+	dto := new(dtos.UpdateDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	result, err := cc.copyService.UpdateCopy(c.UserContext(), c.Params("id"), dto)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": result})
+}
+
+func (cc *CopyController) DeleteCopy(c *fiber.Ctx) error {
+	result, err := cc.copyService.DeleteCopy(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": result})
+}
@@ -0,0 +1,35 @@
+package dtos
+
+import "fiber-app/src/utils"
+
+// CreateDTO represents the structure for adding a copy of a book. Status defaults
+// to available.
+type CreateDTO struct {
+	Barcode   string `json:"barcode" validate:"required,min=1,max=64"`
+	Location  string `json:"location,omitempty" validate:"omitempty,max=200"`
+	Condition string `json:"condition,omitempty" validate:"omitempty,oneof=new good fair poor damaged"`
+	Status    string `json:"status,omitempty" validate:"omitempty,oneof=available maintenance lost withdrawn"`
+}
+
+// UpdateDTO represents the structure for updating a copy. A copy cannot be moved
+// to or from on_loan here; checkouts and returns do that.
+type UpdateDTO struct {
+	Barcode   string `json:"barcode,omitempty" validate:"omitempty,min=1,max=64"`
+	Location  string `json:"location,omitempty" validate:"omitempty,max=200"`
+	Condition string `json:"condition,omitempty" validate:"omitempty,oneof=new good fair poor damaged"`
+	Status    string `json:"status,omitempty" validate:"omitempty,oneof=available maintenance lost withdrawn"`
+}
+
+// CopyFilter holds the query parameters of the copy list endpoint.
+type CopyFilter struct {
+	Status   string `query:"status"`
+	Location string `query:"location"`
+}
+
+func (dto *CreateDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
+
+func (dto *UpdateDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CopyRepository interface {
+	GetCopies(ctx context.Context, filter interface{}) ([]models.Copy, error)
+	GetCopy(ctx context.Context, filter interface{}) (*models.Copy, error)
+	CreateCopy(ctx context.Context, copy *models.Copy) (*mongo.InsertOneResult, error)
+	UpdateCopy(ctx context.Context, filter interface{}, set bson.M) (*models.Copy, error)
+	DeleteCopy(ctx context.Context, filter interface{}) (*models.Copy, error)
+	BookExists(ctx context.Context, bookID primitive.ObjectID) (bool, error)
+	AdjustBookCounts(ctx context.Context, bookID primitive.ObjectID, total int, available int) error
+	WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error)
+}
+
+type copyRepository struct {
+	commonRepo *common.CommonRepository
+	booksRepo  *common.CommonRepository
+}
+
+// NewCopyRepository takes the books collection as well because every change to a
+// copy's status is mirrored in its book's copy counts.
+func NewCopyRepository(collection *mongo.Collection, books *mongo.Collection) CopyRepository {
+	return &copyRepository{
+		commonRepo: common.NewCommonRepository(collection),
+		booksRepo:  common.NewCommonRepository(books),
+	}
+}
+
+func (r *copyRepository) GetCopies(ctx context.Context, filter interface{}) ([]models.Copy, error) {
+	var copies []models.Copy
+	err := r.commonRepo.FindAll(ctx, filter, &copies, options.Find().SetSort(bson.M{"barcode": 1}))
+	return copies, err
+}
+
+func (r *copyRepository) GetCopy(ctx context.Context, filter interface{}) (*models.Copy, error) {
+	var copy models.Copy
+	err := r.commonRepo.FindOne(ctx, filter, &copy)
+	return &copy, err
+}
+
+func (r *copyRepository) CreateCopy(ctx context.Context, copy *models.Copy) (*mongo.InsertOneResult, error) {
+	return r.commonRepo.InsertOne(ctx, copy)
+}
+
+// UpdateCopy sets fields on the copy matching filter and returns the copy as it was
+// before the update, so callers can tell which status it left. It returns
+// mongo.ErrNoDocuments when nothing matched.
+func (r *copyRepository) UpdateCopy(ctx context.Context, filter interface{}, set bson.M) (*models.Copy, error) {
+	res, err := r.commonRepo.FindAndModify(ctx, filter, bson.M{"$set": set}, options.FindOneAndUpdate().SetReturnDocument(options.Before))
+	if err != nil {
+		return nil, err
+	}
+
+	var copy models.Copy
+	err = res.Decode(&copy)
+	return &copy, err
+}
+
+// DeleteCopy removes the copy matching filter and returns it. It returns
+// mongo.ErrNoDocuments when nothing matched.
+func (r *copyRepository) DeleteCopy(ctx context.Context, filter interface{}) (*models.Copy, error) {
+	res, err := r.commonRepo.FindAndDelete(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var copy models.Copy
+	err = res.Decode(&copy)
+	return &copy, err
+}
+
+func (r *copyRepository) BookExists(ctx context.Context, bookID primitive.ObjectID) (bool, error) {
+	return r.booksRepo.Exists(ctx, bson.M{"_id": bookID, "deletedAt": bson.M{"$exists": false}})
+}
+
+// AdjustBookCounts adds total and available to the book's copiesTotal and
+// copiesAvailable.
+func (r *copyRepository) AdjustBookCounts(ctx context.Context, bookID primitive.ObjectID, total int, available int) error {
+	if total == 0 && available == 0 {
+		return nil
+	}
+	_, err := r.booksRepo.UpdateOneRaw(ctx, bson.M{"_id": bookID}, bson.M{
+		"$inc": bson.M{"copiesTotal": total, "copiesAvailable": available},
+	})
+	return err
+}
+
+func (r *copyRepository) WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error) {
+	return r.commonRepo.WithTransaction(ctx, fn)
+}
@@ -0,0 +1,304 @@
+package copyService
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"fiber-app/src/common"
+	"fiber-app/src/copies/dtos"
+	"fiber-app/src/copies/repository"
+	"fiber-app/src/models"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CopyService struct {
+	repo repository.CopyRepository
+}
+
+// Releaser puts a copy coming back from status from on the shelf, or hands it to
+// the next reader waiting for its book. The holds module registers one, since it
+// depends on this package rather than the other way round.
+type Releaser func(ctx context.Context, copyID primitive.ObjectID, bookID primitive.ObjectID, from string) error
+
+var (
+	releaserMu sync.RWMutex
+	releaser   Releaser
+)
+
+// RegisterReleaser makes copies that are added or put back as available go through
+// r. Modules register it while routes are being set up.
+func RegisterReleaser(r Releaser) {
+	releaserMu.Lock()
+	defer releaserMu.Unlock()
+	releaser = r
+}
+
+// NewCopyService initializes the repository and returns a new CopyService instance.
+func NewCopyService() *CopyService {
+	repo := repository.NewCopyRepository(common.GetDBCollection("copies"), common.GetDBCollection("books"))
+	return &CopyService{repo: repo}
+}
+
+func (s *CopyService) GetCopiesByBook(ctx context.Context, bookID string, filter *dtos.CopyFilter) ([]models.Copy, error) {
+	objectID, err := primitive.ObjectIDFromHex(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := bson.M{"bookId": objectID}
+	if filter != nil && filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter != nil && filter.Location != "" {
+		query["location"] = filter.Location
+	}
+	return s.repo.GetCopies(ctx, query)
+}
+
+func (s *CopyService) GetCopyByID(ctx context.Context, id string) (*models.Copy, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetCopy(ctx, bson.M{"_id": objectID})
+}
+
+// GetCopy returns the copy matching filter, e.g. its ID or barcode.
+func (s *CopyService) GetCopy(ctx context.Context, filter bson.M) (*models.Copy, error) {
+	return s.repo.GetCopy(ctx, filter)
+}
+
+// CreateCopy adds a copy to a book and bumps the book's copy counts in the same
+// transaction. An available copy goes to the next reader waiting for the book first.
+func (s *CopyService) CreateCopy(ctx context.Context, bookID string, dto *dtos.CreateDTO) (*models.Copy, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+	bookObjectID, err := primitive.ObjectIDFromHex(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	copy := &models.Copy{
+		ID:        primitive.NewObjectID(),
+		BookID:    bookObjectID,
+		Barcode:   strings.TrimSpace(dto.Barcode),
+		Location:  strings.TrimSpace(dto.Location),
+		Condition: dto.Condition,
+		Status:    dto.Status,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if copy.Condition == "" {
+		copy.Condition = models.CopyConditionGood
+	}
+	if copy.Status == "" {
+		copy.Status = models.CopyStatusAvailable
+	}
+
+	result, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		exists, err := s.repo.BookExists(sessCtx, bookObjectID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, mongo.ErrNoDocuments
+		}
+
+		// An available copy is added under maintenance and released from there, so
+		// a waiting reader gets it before it reaches the shelf.
+		inserted := *copy
+		if copy.Status == models.CopyStatusAvailable {
+			inserted.Status = models.CopyStatusMaintenance
+		}
+		if _, err := s.repo.CreateCopy(sessCtx, &inserted); err != nil {
+			return nil, err
+		}
+		if err := s.repo.AdjustBookCounts(sessCtx, bookObjectID, 1, availableCount(inserted.Status)); err != nil {
+			return nil, err
+		}
+		if inserted.Status == copy.Status {
+			return &inserted, nil
+		}
+		if err := s.release(sessCtx, inserted.ID, bookObjectID, inserted.Status); err != nil {
+			return nil, err
+		}
+		return s.repo.GetCopy(sessCtx, bson.M{"_id": inserted.ID})
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, utils.Conflict("barcode_taken", "a copy with barcode "+copy.Barcode+" already exists")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.(*models.Copy), nil
+}
+
+// UpdateCopy changes a copy's details. Status changes are applied only if the copy
+// still has the status that was read, so a concurrent checkout cannot be overwritten.
+// A copy put back as available goes to the next reader waiting for its book first.
+func (s *CopyService) UpdateCopy(ctx context.Context, id string, dto *dtos.UpdateDTO) (*models.Copy, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{"updatedAt": time.Now().UTC()}
+	if dto.Barcode != "" {
+		set["barcode"] = strings.TrimSpace(dto.Barcode)
+	}
+	if dto.Location != "" {
+		set["location"] = strings.TrimSpace(dto.Location)
+	}
+	if dto.Condition != "" {
+		set["condition"] = dto.Condition
+	}
+	if dto.Status != "" {
+		set["status"] = dto.Status
+	}
+
+	_, err = s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		current, err := s.repo.GetCopy(sessCtx, bson.M{"_id": objectID})
+		if err != nil {
+			return nil, err
+		}
+		if dto.Status != "" && dto.Status != current.Status && !manuallySettable(current.Status) {
+			return nil, utils.Conflict("copy_unavailable", "copy is "+current.Status+" and cannot change status")
+		}
+
+		// The callback may be retried, so set is copied rather than changed.
+		update := bson.M{}
+		for k, v := range set {
+			update[k] = v
+		}
+		release := dto.Status == models.CopyStatusAvailable && current.Status != models.CopyStatusAvailable
+		if release {
+			delete(update, "status")
+		}
+
+		previous, err := s.repo.UpdateCopy(sessCtx, bson.M{"_id": objectID, "status": current.Status}, update)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, utils.Conflict("copy_changed", "copy was changed concurrently, retry")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if release {
+			return nil, s.release(sessCtx, previous.ID, previous.BookID, previous.Status)
+		}
+		if dto.Status == "" {
+			return nil, nil
+		}
+		delta := availableCount(dto.Status) - availableCount(previous.Status)
+		return nil, s.repo.AdjustBookCounts(sessCtx, previous.BookID, 0, delta)
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, utils.Conflict("barcode_taken", "a copy with barcode "+dto.Barcode+" already exists")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetCopy(ctx, bson.M{"_id": objectID})
+}
+
+// DeleteCopy removes a copy that is neither on loan nor on hold and takes it off its
+// book's counts.
+func (s *CopyService) DeleteCopy(ctx context.Context, id string) (*models.Copy, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		copy, err := s.repo.DeleteCopy(sessCtx, bson.M{
+			"_id":    objectID,
+			"status": bson.M{"$nin": bson.A{models.CopyStatusOnLoan, models.CopyStatusOnHold}},
+		})
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, findErr := s.repo.GetCopy(sessCtx, bson.M{"_id": objectID}); findErr != nil {
+				return nil, findErr
+			}
+			return nil, utils.Conflict("copy_unavailable", "copy is on loan or on hold and cannot be deleted")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return copy, s.repo.AdjustBookCounts(sessCtx, copy.BookID, -1, -availableCount(copy.Status))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted.(*models.Copy), nil
+}
+
+// CheckOut marks the available copy matching filter as on loan and takes it off its
+// book's available count. Run it inside the loan's transaction.
+func (s *CopyService) CheckOut(ctx context.Context, filter bson.M) (*models.Copy, error) {
+	return s.MoveCopy(ctx, filter, models.CopyStatusAvailable, models.CopyStatusOnLoan)
+}
+
+// MoveCopy changes the status of the copy matching filter from one status to
+// another and keeps its book's available count in step. It fails with
+// copy_unavailable when the copy is not in the from status. Loans and holds use it
+// inside their transactions.
+func (s *CopyService) MoveCopy(ctx context.Context, filter bson.M, from, to string) (*models.Copy, error) {
+	query := bson.M{"status": from}
+	for k, v := range filter {
+		query[k] = v
+	}
+
+	copy, err := s.repo.UpdateCopy(ctx, query, bson.M{"status": to, "updatedAt": time.Now().UTC()})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		current, findErr := s.repo.GetCopy(ctx, filter)
+		if findErr != nil {
+			return nil, findErr
+		}
+		return nil, utils.Conflict("copy_unavailable", "copy "+current.Barcode+" is "+current.Status)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AdjustBookCounts(ctx, copy.BookID, 0, availableCount(to)-availableCount(from)); err != nil {
+		return nil, err
+	}
+	copy.Status = to
+	return copy, nil
+}
+
+// release puts a copy coming back from status from on the shelf, through the
+// registered Releaser when there is one. Run it inside the caller's transaction.
+func (s *CopyService) release(ctx context.Context, copyID primitive.ObjectID, bookID primitive.ObjectID, from string) error {
+	releaserMu.RLock()
+	r := releaser
+	releaserMu.RUnlock()
+	if r == nil {
+		_, err := s.MoveCopy(ctx, bson.M{"_id": copyID}, from, models.CopyStatusAvailable)
+		return err
+	}
+	return r(ctx, copyID, bookID, from)
+}
+
+func availableCount(status string) int {
+	if status == models.CopyStatusAvailable {
+		return 1
+	}
+	return 0
+}
+
+// manuallySettable reports whether staff may move a copy out of status by hand.
+func manuallySettable(status string) bool {
+	return status != models.CopyStatusOnLoan && status != models.CopyStatusOnHold
+}
@@ -0,0 +1,69 @@
+package finesController
+
+import (
+	"fiber-app/src/auth"
+	"fiber-app/src/fines/dtos"
+	fineService "fiber-app/src/fines/services"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FineController struct {
+	fineService *fineService.FineService
+}
+
+func NewFineController() *FineController {
+	return &FineController{
+		fineService: fineService.NewFineService(),
+	}
+}
+
+// RegisterTasks schedules the overdue loan scan on this instance.
+func (fc *FineController) RegisterTasks() {
+	fc.fineService.RegisterTasks()
+}
+
+// GetMyFines returns the caller's balance and fine ledger.
+func (fc *FineController) GetMyFines(c *fiber.Ctx) error {
+	summary, err := fc.fineService.GetFines(c.UserContext(), auth.CurrentUser(c).ID)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": summary})
+}
+
+// GetUserFines returns the balance and fine ledger of the user in the :id param.
+func (fc *FineController) GetUserFines(c *fiber.Ctx) error {
+	summary, err := fc.fineService.GetFines(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": summary})
+}
+
+func (fc *FineController) PayFines(c *fiber.Ctx) error {
+	dto := new(dtos.SettleDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	summary, err := fc.fineService.PayFines(c.UserContext(), auth.CurrentUser(c), c.Params("id"), dto)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": summary})
+}
+
+func (fc *FineController) WaiveFines(c *fiber.Ctx) error {
+	dto := new(dtos.SettleDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	summary, err := fc.fineService.WaiveFines(c.UserContext(), auth.CurrentUser(c), c.Params("id"), dto)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": summary})
+}
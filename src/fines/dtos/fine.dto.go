@@ -0,0 +1,25 @@
+package dtos
+
+import (
+	"fiber-app/src/models"
+	"fiber-app/src/utils"
+)
+
+// SettleDTO is the body of the pay and waive endpoints. Amount is in cents; leaving
+// it out settles the whole balance.
+type SettleDTO struct {
+	Amount int64  `json:"amount,omitempty" validate:"omitempty,min=1"`
+	Note   string `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
+// FineSummary is a patron's balance together with their ledger, newest first.
+type FineSummary struct {
+	UserID  string             `json:"userId"`
+	Balance int64              `json:"balance"`
+	Blocked bool               `json:"blocked"` // Whether the balance is high enough to block checkouts.
+	Entries []models.FineEntry `json:"entries"`
+}
+
+func (dto *SettleDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type FineRepository interface {
+	GetEntries(ctx context.Context, userID string) ([]models.FineEntry, error)
+	CreateEntry(ctx context.Context, entry *models.FineEntry) (*mongo.InsertOneResult, error)
+	GetPatron(ctx context.Context, userID string) (*models.Patron, error)
+	ChargeBalance(ctx context.Context, userID string, amount int64) error
+	CreditBalance(ctx context.Context, userID string, amount int64) (*models.Patron, error)
+	GetOverdueLoans(ctx context.Context, now time.Time) ([]models.Loan, error)
+	SetLoanFine(ctx context.Context, loanID primitive.ObjectID, previous int64, amount int64) (bool, error)
+	WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error)
+}
+
+type fineRepository struct {
+	commonRepo  *common.CommonRepository
+	loansRepo   *common.CommonRepository
+	patronsRepo *common.CommonRepository
+}
+
+// NewFineRepository takes the loans collection, which it scans for overdue loans,
+// and the patrons collection, which holds each user's balance.
+func NewFineRepository(collection *mongo.Collection, loans *mongo.Collection, patrons *mongo.Collection) FineRepository {
+	return &fineRepository{
+		commonRepo:  common.NewCommonRepository(collection),
+		loansRepo:   common.NewCommonRepository(loans),
+		patronsRepo: common.NewCommonRepository(patrons),
+	}
+}
+
+func (r *fineRepository) GetEntries(ctx context.Context, userID string) ([]models.FineEntry, error) {
+	var entries []models.FineEntry
+	err := r.commonRepo.FindAll(ctx, bson.M{"userId": userID}, &entries, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	return entries, err
+}
+
+func (r *fineRepository) CreateEntry(ctx context.Context, entry *models.FineEntry) (*mongo.InsertOneResult, error) {
+	return r.commonRepo.InsertOne(ctx, entry)
+}
+
+func (r *fineRepository) GetPatron(ctx context.Context, userID string) (*models.Patron, error) {
+	var patron models.Patron
+	err := r.patronsRepo.FindOne(ctx, bson.M{"_id": userID}, &patron)
+	return &patron, err
+}
+
+// ChargeBalance adds amount to the user's balance, creating the patron on first use.
+func (r *fineRepository) ChargeBalance(ctx context.Context, userID string, amount int64) error {
+	now := time.Now().UTC()
+	_, err := r.patronsRepo.UpdateOneRaw(ctx, bson.M{"_id": userID}, bson.M{
+		"$inc":         bson.M{"finesOwed": amount},
+		"$set":         bson.M{"updatedAt": now},
+		"$setOnInsert": bson.M{"createdAt": now, "activeLoans": 0},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+// CreditBalance takes amount off the user's balance unless that would make it
+// negative, and returns the patron after the change. It returns
+// mongo.ErrNoDocuments when the balance is smaller than amount.
+func (r *fineRepository) CreditBalance(ctx context.Context, userID string, amount int64) (*models.Patron, error) {
+	update := bson.M{"$inc": bson.M{"finesOwed": -amount}, "$set": bson.M{"updatedAt": time.Now().UTC()}}
+	res, err := r.patronsRepo.FindAndModify(ctx,
+		bson.M{"_id": userID, "finesOwed": bson.M{"$gte": amount}},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var patron models.Patron
+	err = res.Decode(&patron)
+	return &patron, err
+}
+
+func (r *fineRepository) GetOverdueLoans(ctx context.Context, now time.Time) ([]models.Loan, error) {
+	var loans []models.Loan
+	err := r.loansRepo.FindAll(ctx, bson.M{"status": models.LoanStatusActive, "dueAt": bson.M{"$lt": now}}, &loans)
+	return loans, err
+}
+
+// SetLoanFine records amount as the loan's fine so far, provided it still is
+// previous, and reports whether it did. Loans that were never fined have no
+// fineAccrued field at all.
+func (r *fineRepository) SetLoanFine(ctx context.Context, loanID primitive.ObjectID, previous int64, amount int64) (bool, error) {
+	filter := bson.M{"_id": loanID, "fineAccrued": previous}
+	if previous == 0 {
+		filter["fineAccrued"] = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	res, err := r.loansRepo.UpdateOne(ctx, filter, bson.M{"fineAccrued": amount})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+func (r *fineRepository) WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error) {
+	return r.commonRepo.WithTransaction(ctx, fn)
+}
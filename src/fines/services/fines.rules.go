@@ -0,0 +1,73 @@
+package fineService
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"fiber-app/src/auth"
+	"fiber-app/src/config"
+)
+
+// FineRule is how overdue loans of borrowers with a given role are fined. Amounts
+// are in cents.
+type FineRule struct {
+	DailyRate int64 `json:"dailyRate"` // Charged per started day past the grace period.
+	GraceDays int   `json:"graceDays"` // Days past the due date that are not charged.
+	Cap       int64 `json:"cap"`       // Most a single loan can be fined; 0 means no cap.
+}
+
+var defaultFineRules = map[string]FineRule{
+	auth.RoleMember: {DailyRate: 25, GraceDays: 1, Cap: 1000},
+	auth.RoleStaff:  {DailyRate: 10, GraceDays: 3, Cap: 500},
+	auth.RoleAdmin:  {DailyRate: 10, GraceDays: 3, Cap: 500},
+}
+
+// FineFor returns the fine for a loan due at dueAt that is returned, or looked at,
+// at until.
+func (r FineRule) FineFor(dueAt, until time.Time) int64 {
+	overdue := until.Sub(dueAt)
+	if overdue <= 0 {
+		return 0
+	}
+
+	day := 24 * time.Hour
+	days := int64((overdue+day-1)/day) - int64(r.GraceDays)
+	if days <= 0 {
+		return 0
+	}
+
+	fine := days * r.DailyRate
+	if r.Cap > 0 && fine > r.Cap {
+		fine = r.Cap
+	}
+	return fine
+}
+
+// loadFineRules returns the fine rules, the defaults overridden by cfg.Rules, and
+// the block threshold, the balance above which checkouts are refused.
+func loadFineRules(cfg config.FinesConfig) (map[string]FineRule, int64, error) {
+	rules := make(map[string]FineRule, len(defaultFineRules))
+	for role, rule := range defaultFineRules {
+		rules[role] = rule
+	}
+	threshold := cfg.BlockThreshold
+
+	if raw := cfg.Rules; raw != "" {
+		var overrides map[string]FineRule
+		if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+			return rules, threshold, fmt.Errorf("invalid FINE_RULES: %w", err)
+		}
+		for role, rule := range overrides {
+			if !auth.ValidRole(role) {
+				return rules, threshold, fmt.Errorf("invalid FINE_RULES: unknown role %q", role)
+			}
+			if rule.DailyRate < 0 || rule.GraceDays < 0 || rule.Cap < 0 {
+				return rules, threshold, fmt.Errorf("invalid FINE_RULES: negative value for role %q", role)
+			}
+			rules[role] = rule
+		}
+	}
+
+	return rules, threshold, nil
+}
@@ -0,0 +1,76 @@
+package fineService
+
+import (
+	"testing"
+	"time"
+
+	"fiber-app/src/auth"
+	"fiber-app/src/config"
+)
+
+func TestFineFor(t *testing.T) {
+	due := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	rule := FineRule{DailyRate: 25, GraceDays: 1, Cap: 1000}
+
+	tests := []struct {
+		name  string
+		rule  FineRule
+		until time.Time
+		want  int64
+	}{
+		{"returned early", rule, due.Add(-time.Hour), 0},
+		{"returned on time", rule, due, 0},
+		{"within grace period", rule, due.Add(day), 0},
+		{"started day after grace is charged", rule, due.Add(day + time.Minute), 25},
+		{"several days", rule, due.Add(5 * day), 100},
+		{"capped", rule, due.Add(60 * day), 1000},
+		{"no grace period", FineRule{DailyRate: 10}, due.Add(time.Second), 10},
+		{"no cap", FineRule{DailyRate: 10}, due.Add(365 * day), 3650},
+		{"grace longer than overdue", FineRule{DailyRate: 10, GraceDays: 30}, due.Add(10 * day), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.FineFor(due, tt.until); got != tt.want {
+				t.Errorf("FineFor(%v) = %d, want %d", tt.until.Sub(due), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFineRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.FinesConfig
+		member  FineRule
+		wantErr bool
+	}{
+		{"defaults", config.FinesConfig{BlockThreshold: 1000}, defaultFineRules[auth.RoleMember], false},
+		{"override", config.FinesConfig{Rules: `{"member":{"dailyRate":50,"graceDays":0,"cap":2000}}`}, FineRule{DailyRate: 50, Cap: 2000}, false},
+		{"other roles keep defaults", config.FinesConfig{Rules: `{"staff":{"dailyRate":5}}`}, defaultFineRules[auth.RoleMember], false},
+		{"invalid json", config.FinesConfig{Rules: `{`}, defaultFineRules[auth.RoleMember], true},
+		{"unknown role", config.FinesConfig{Rules: `{"guest":{"dailyRate":5}}`}, defaultFineRules[auth.RoleMember], true},
+		{"negative value", config.FinesConfig{Rules: `{"member":{"dailyRate":-5}}`}, defaultFineRules[auth.RoleMember], true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, threshold, err := loadFineRules(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadFineRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if threshold != tt.cfg.BlockThreshold {
+				t.Errorf("threshold = %d, want %d", threshold, tt.cfg.BlockThreshold)
+			}
+			if rules[auth.RoleMember] != tt.member {
+				t.Errorf("member rule = %+v, want %+v", rules[auth.RoleMember], tt.member)
+			}
+			for role := range defaultFineRules {
+				if _, ok := rules[role]; !ok {
+					t.Errorf("no rule for role %q", role)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package fineService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"fiber-app/src/auth"
+	"fiber-app/src/common"
+	"fiber-app/src/config"
+	"fiber-app/src/fines/dtos"
+	"fiber-app/src/fines/repository"
+	"fiber-app/src/logging"
+	"fiber-app/src/models"
+	"fiber-app/src/scheduler"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const overdueScanInterval = 15 * time.Minute
+
+type FineService struct {
+	repo           repository.FineRepository
+	rules          map[string]FineRule
+	blockThreshold int64
+}
+
+var logger = logging.For("fines")
+
+// NewFineService initializes the repository and returns a new FineService instance.
+// Invalid fine settings are reported and the defaults are used instead.
+func NewFineService() *FineService {
+	repo := repository.NewFineRepository(common.GetDBCollection("fines"), common.GetDBCollection("loans"), common.GetDBCollection("patrons"))
+	rules, threshold, err := loadFineRules(config.Get().Fines)
+	if err != nil {
+		logger.Error("loading fine rules, using defaults", logging.Err(err))
+	}
+	return &FineService{repo: repo, rules: rules, blockThreshold: threshold}
+}
+
+// RegisterTasks schedules the scan that fines overdue loans.
+func (s *FineService) RegisterTasks() {
+	scheduler.Register("loans.overdue", overdueScanInterval, s.ScanOverdueLoans)
+}
+
+// RuleFor returns the fine rule of role, falling back to the member rule.
+func (s *FineService) RuleFor(role string) FineRule {
+	if rule, ok := s.rules[role]; ok {
+		return rule
+	}
+	return s.rules[auth.RoleMember]
+}
+
+// CheckCanBorrow refuses patrons whose outstanding fines are above the threshold.
+func (s *FineService) CheckCanBorrow(patron *models.Patron) error {
+	if patron.FinesOwed > s.blockThreshold {
+		return utils.NewError(http.StatusForbidden, "fines_outstanding", fmt.Sprintf("outstanding fines of %d cents must be paid first", patron.FinesOwed))
+	}
+	return nil
+}
+
+// ScanOverdueLoans brings the fine of every overdue loan up to date. Each loan is
+// charged in its own transaction and only if nobody charged it in between, so
+// instances scanning at the same time cannot charge a loan twice. A loan that
+// cannot be charged is logged and skipped so it does not hold up the rest; the
+// scan then reports how many failed.
+func (s *FineService) ScanOverdueLoans(ctx context.Context) error {
+	now := time.Now().UTC()
+	loans, err := s.repo.GetOverdueLoans(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i := range loans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		loan := &loans[i]
+		// The driver may run the callback again, so each attempt charges a fresh copy
+		// of the loan as it was read and the loan only takes the result on commit.
+		accrued, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			charged := *loan
+			if err := s.Accrue(sessCtx, &charged, now); err != nil {
+				return nil, err
+			}
+			return charged.FineAccrued, nil
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "fining overdue loan", "loan_id", loan.ID.Hex(), logging.Err(err))
+			failed++
+			continue
+		}
+		loan.FineAccrued = accrued.(int64)
+	}
+	if failed > 0 {
+		return fmt.Errorf("fining overdue loans: %d of %d failed", failed, len(loans))
+	}
+	return nil
+}
+
+// Accrue charges the part of the loan's fine at until that has not been charged yet
+// and records it in the ledger. Run it inside a transaction; the return of a loan
+// calls it with the return time so the fine stops growing there. It sets the loan's
+// FineAccrued to the new amount, so a transaction callback that may be retried has
+// to pass a loan it read or copied itself.
+func (s *FineService) Accrue(ctx context.Context, loan *models.Loan, until time.Time) error {
+	amount := s.RuleFor(loan.Role).FineFor(loan.DueAt, until)
+	delta := amount - loan.FineAccrued
+	if delta <= 0 {
+		return nil
+	}
+
+	updated, err := s.repo.SetLoanFine(ctx, loan.ID, loan.FineAccrued, amount)
+	if err != nil || !updated {
+		return err
+	}
+
+	loanID := loan.ID
+	entry := &models.FineEntry{
+		UserID:    loan.UserID,
+		LoanID:    &loanID,
+		Type:      models.FineEntryCharge,
+		Amount:    delta,
+		Note:      fmt.Sprintf("overdue since %s", loan.DueAt.Format("2006-01-02")),
+		CreatedAt: time.Now().UTC(),
+	}
+	if _, err := s.repo.CreateEntry(ctx, entry); err != nil {
+		return err
+	}
+	if err := s.repo.ChargeBalance(ctx, loan.UserID, delta); err != nil {
+		return err
+	}
+	loan.FineAccrued = amount
+	return nil
+}
+
+// GetFines returns a user's balance and ledger.
+func (s *FineService) GetFines(ctx context.Context, userID string) (*dtos.FineSummary, error) {
+	summary := &dtos.FineSummary{UserID: userID, Entries: []models.FineEntry{}}
+
+	patron, err := s.repo.GetPatron(ctx, userID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if err == nil {
+		summary.Balance = patron.FinesOwed
+		summary.Blocked = s.CheckCanBorrow(patron) != nil
+	}
+
+	entries, err := s.repo.GetEntries(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if entries != nil {
+		summary.Entries = entries
+	}
+	return summary, nil
+}
+
+// PayFines records a payment against a user's balance.
+func (s *FineService) PayFines(ctx context.Context, admin *auth.User, userID string, dto *dtos.SettleDTO) (*dtos.FineSummary, error) {
+	return s.settle(ctx, admin, userID, dto, models.FineEntryPayment)
+}
+
+// WaiveFines forgives part or all of a user's balance.
+func (s *FineService) WaiveFines(ctx context.Context, admin *auth.User, userID string, dto *dtos.SettleDTO) (*dtos.FineSummary, error) {
+	return s.settle(ctx, admin, userID, dto, models.FineEntryWaiver)
+}
+
+// settle takes dto.Amount, or the whole balance, off the user's balance and records
+// it in the ledger as entryType. The balance can never go below zero.
+func (s *FineService) settle(ctx context.Context, admin *auth.User, userID string, dto *dtos.SettleDTO, entryType string) (*dtos.FineSummary, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+
+	_, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		amount := dto.Amount
+		if amount == 0 {
+			patron, err := s.repo.GetPatron(sessCtx, userID)
+			if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, err
+			}
+			amount = patron.FinesOwed
+		}
+		if amount == 0 {
+			return nil, utils.Conflict("nothing_owed", "user has no outstanding fines")
+		}
+
+		if _, err := s.repo.CreditBalance(sessCtx, userID, amount); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, utils.Conflict("amount_exceeds_balance", "amount is more than the outstanding fines")
+			}
+			return nil, err
+		}
+
+		entry := &models.FineEntry{
+			UserID:    userID,
+			Type:      entryType,
+			Amount:    -amount,
+			Note:      dto.Note,
+			CreatedBy: admin.ID,
+			CreatedAt: time.Now().UTC(),
+		}
+		_, err := s.repo.CreateEntry(sessCtx, entry)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetFines(ctx, userID)
+}
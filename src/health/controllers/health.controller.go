@@ -0,0 +1,45 @@
+package healthController
+
+import (
+	"fiber-app/src/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthController serves the probe endpoints. They answer with bare bodies rather
+// than {"data": ...}, which is what load balancers and orchestrators expect.
+type HealthController struct{}
+
+func NewHealthController() *HealthController {
+	return &HealthController{}
+}
+
+// Liveness answers as long as the process can serve requests at all. It checks no
+// dependencies, so an outage of one does not get the process restarted.
+func (hc *HealthController) Liveness(c *fiber.Ctx) error {
+	return c.Status(200).JSON(fiber.Map{"status": health.StatusUp})
+}
+
+// Readiness answers 503 while the process is draining or any check fails, so no new
+// traffic is routed here.
+func (hc *HealthController) Readiness(c *fiber.Ctx) error {
+	if health.Draining() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": health.StatusDraining})
+	}
+
+	report := health.Run(c.UserContext())
+	if !report.Ready() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(report)
+	}
+	return c.Status(200).JSON(fiber.Map{"status": report.Status})
+}
+
+// Report runs every check and returns each one's status and latency.
+func (hc *HealthController) Report(c *fiber.Ctx) error {
+	report := health.Run(c.UserContext())
+	status := 200
+	if !report.Ready() {
+		status = fiber.StatusServiceUnavailable
+	}
+	return c.Status(status).JSON(report)
+}
@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of a check and of a whole report.
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDraining = "draining" // The process is shutting down and takes no new traffic.
+)
+
+// checkTimeout bounds each check, so one hung dependency cannot stall a probe.
+const checkTimeout = 2 * time.Second
+
+// HealthChecker reports whether a dependency the app needs is usable.
+type HealthChecker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to a HealthChecker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type namedChecker struct {
+	name    string
+	checker HealthChecker
+}
+
+var (
+	checkersMu sync.Mutex
+	checkers   []namedChecker
+	draining   atomic.Bool
+)
+
+// Register adds a checker that readiness and the health report run. Modules register
+// their dependencies at startup, like scheduled tasks.
+func Register(name string, checker HealthChecker) {
+	checkersMu.Lock()
+	defer checkersMu.Unlock()
+	checkers = append(checkers, namedChecker{name: name, checker: checker})
+}
+
+// SetDraining marks the process as shutting down, which fails readiness from then on.
+func SetDraining() {
+	draining.Store(true)
+}
+
+// Draining reports whether SetDraining has been called.
+func Draining() bool {
+	return draining.Load()
+}
+
+// CheckResult is the outcome of one checker.
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of every registered checker. Status is up only when every
+// check is, and draining during shutdown.
+type Report struct {
+	Status    string                 `json:"status"`
+	CheckedAt time.Time              `json:"checkedAt"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+// Ready reports whether the app should receive traffic.
+func (r *Report) Ready() bool {
+	return r.Status == StatusUp
+}
+
+// Run runs the registered checkers concurrently and collects their results.
+func Run(ctx context.Context) *Report {
+	checkersMu.Lock()
+	registered := append([]namedChecker(nil), checkers...)
+	checkersMu.Unlock()
+	sort.Slice(registered, func(i, j int) bool { return registered[i].name < registered[j].name })
+
+	results := make([]CheckResult, len(registered))
+	var wg sync.WaitGroup
+	for i, c := range registered {
+		wg.Add(1)
+		go func(i int, c namedChecker) {
+			defer wg.Done()
+			results[i] = check(ctx, c.checker)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusUp, CheckedAt: time.Now().UTC(), Checks: make(map[string]CheckResult, len(registered))}
+	for i, c := range registered {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	if Draining() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+func check(ctx context.Context, checker HealthChecker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	result := CheckResult{Status: StatusUp, LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
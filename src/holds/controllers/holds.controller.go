@@ -0,0 +1,76 @@
+package holdsController
+
+import (
+	"fiber-app/src/auth"
+	"fiber-app/src/holds/dtos"
+	holdService "fiber-app/src/holds/services"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HoldController struct {
+	holdService *holdService.HoldService
+}
+
+func NewHoldController() *HoldController {
+	return &HoldController{
+		holdService: holdService.NewHoldService(),
+	}
+}
+
+// RegisterTasks schedules hold expiry on this instance.
+func (hc *HoldController) RegisterTasks() {
+	hc.holdService.RegisterTasks()
+}
+
+// RegisterReleaser hands copies added or put back on the shelf to waiting readers.
+func (hc *HoldController) RegisterReleaser() {
+	hc.holdService.RegisterReleaser()
+}
+
+func (hc *HoldController) GetHolds(c *fiber.Ctx) error {
+	filter := new(dtos.HoldFilter)
+	if err := utils.ParseQuery(c, filter); err != nil {
+		return err
+	}
+
+	holds, err := hc.holdService.GetHolds(c.UserContext(), auth.CurrentUser(c), filter)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": holds})
+}
+
+func (hc *HoldController) GetHold(c *fiber.Ctx) error {
+	hold, err := hc.holdService.GetHold(c.UserContext(), auth.CurrentUser(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": hold})
+}
+
+// PlaceHold queues the caller, or userId when placed by staff, for a book with no
+// copy on the shelf.
+func (hc *HoldController) PlaceHold(c *fiber.Ctx) error {
+	dto := new(dtos.CreateDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	hold, err := hc.holdService.PlaceHold(c.UserContext(), auth.CurrentUser(c), dto)
+	if err != nil {
+		return err
+	}
+
+	c.Location("/holds/" + hold.ID.Hex())
+	return c.Status(201).JSON(fiber.Map{"result": hold})
+}
+
+func (hc *HoldController) CancelHold(c *fiber.Ctx) error {
+	hold, err := hc.holdService.CancelHold(c.UserContext(), auth.CurrentUser(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": hold})
+}
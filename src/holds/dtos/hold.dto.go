@@ -0,0 +1,22 @@
+package dtos
+
+import "fiber-app/src/utils"
+
+// CreateDTO is the body of POST /holds. Staff can place a hold for another user by
+// setting UserID.
+type CreateDTO struct {
+	BookID string `json:"bookId" validate:"required,objectid"`
+	UserID string `json:"userId,omitempty" validate:"omitempty,max=128"`
+}
+
+// HoldFilter holds the query parameters of the hold list endpoint. Members only
+// ever see their own holds, so UserID is for staff.
+type HoldFilter struct {
+	UserID string `query:"userId"`
+	BookID string `query:"bookId"`
+	Status string `query:"status"`
+}
+
+func (dto *CreateDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
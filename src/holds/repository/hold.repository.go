@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// queueOrder is the order in which waiting holds are served.
+var queueOrder = bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}
+
+type HoldRepository interface {
+	GetHolds(ctx context.Context, filter interface{}) ([]models.Hold, error)
+	GetHoldByID(ctx context.Context, id string) (*models.Hold, error)
+	CreateHold(ctx context.Context, hold *models.Hold) (*mongo.InsertOneResult, error)
+	UpdateHold(ctx context.Context, filter interface{}, set bson.M) (*models.Hold, error)
+	HoldExists(ctx context.Context, filter interface{}) (bool, error)
+	CountAhead(ctx context.Context, hold *models.Hold) (int64, error)
+	AssignNext(ctx context.Context, bookID primitive.ObjectID, copyID primitive.ObjectID, pickupBy time.Time) (*models.Hold, error)
+	GetBook(ctx context.Context, bookID primitive.ObjectID) (*models.Book, error)
+	WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error)
+}
+
+type holdRepository struct {
+	commonRepo *common.CommonRepository
+	booksRepo  *common.CommonRepository
+}
+
+// NewHoldRepository takes the books collection as well to check a book's copy
+// counts before a hold is placed.
+func NewHoldRepository(collection *mongo.Collection, books *mongo.Collection) HoldRepository {
+	return &holdRepository{
+		commonRepo: common.NewCommonRepository(collection),
+		booksRepo:  common.NewCommonRepository(books),
+	}
+}
+
+func (r *holdRepository) GetHolds(ctx context.Context, filter interface{}) ([]models.Hold, error) {
+	var holds []models.Hold
+	err := r.commonRepo.FindAll(ctx, filter, &holds, options.Find().SetSort(queueOrder))
+	return holds, err
+}
+
+func (r *holdRepository) GetHoldByID(ctx context.Context, id string) (*models.Hold, error) {
+	objectID, err := r.commonRepo.ConvertID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var hold models.Hold
+	err = r.commonRepo.FindOne(ctx, bson.M{"_id": objectID}, &hold)
+	return &hold, err
+}
+
+func (r *holdRepository) CreateHold(ctx context.Context, hold *models.Hold) (*mongo.InsertOneResult, error) {
+	return r.commonRepo.InsertOne(ctx, hold)
+}
+
+// UpdateHold sets fields on the hold matching filter and returns it after the
+// update. It returns mongo.ErrNoDocuments when nothing matched.
+func (r *holdRepository) UpdateHold(ctx context.Context, filter interface{}, set bson.M) (*models.Hold, error) {
+	res, err := r.commonRepo.FindAndModify(ctx, filter, bson.M{"$set": set}, options.FindOneAndUpdate().SetReturnDocument(options.After))
+	if err != nil {
+		return nil, err
+	}
+
+	var hold models.Hold
+	err = res.Decode(&hold)
+	return &hold, err
+}
+
+func (r *holdRepository) HoldExists(ctx context.Context, filter interface{}) (bool, error) {
+	return r.commonRepo.Exists(ctx, filter)
+}
+
+// CountAhead counts the waiting holds on the same book that will be served before hold.
+func (r *holdRepository) CountAhead(ctx context.Context, hold *models.Hold) (int64, error) {
+	return r.commonRepo.Count(ctx, bson.M{
+		"bookId": hold.BookID,
+		"status": models.HoldStatusWaiting,
+		"$or": bson.A{
+			bson.M{"createdAt": bson.M{"$lt": hold.CreatedAt}},
+			bson.M{"createdAt": hold.CreatedAt, "_id": bson.M{"$lt": hold.ID}},
+		},
+	})
+}
+
+// AssignNext gives copyID to the oldest waiting hold on the book and returns that
+// hold. It returns mongo.ErrNoDocuments when nobody is waiting.
+func (r *holdRepository) AssignNext(ctx context.Context, bookID primitive.ObjectID, copyID primitive.ObjectID, pickupBy time.Time) (*models.Hold, error) {
+	now := time.Now().UTC()
+	update := bson.M{"$set": bson.M{
+		"status":    models.HoldStatusReady,
+		"copyId":    copyID,
+		"readyAt":   now,
+		"pickupBy":  pickupBy,
+		"updatedAt": now,
+	}}
+	opts := options.FindOneAndUpdate().SetSort(queueOrder).SetReturnDocument(options.After)
+
+	res, err := r.commonRepo.FindAndModify(ctx, bson.M{"bookId": bookID, "status": models.HoldStatusWaiting}, update, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var hold models.Hold
+	err = res.Decode(&hold)
+	return &hold, err
+}
+
+func (r *holdRepository) GetBook(ctx context.Context, bookID primitive.ObjectID) (*models.Book, error) {
+	var book models.Book
+	err := r.booksRepo.FindOne(ctx, bson.M{"_id": bookID, "deletedAt": bson.M{"$exists": false}}, &book)
+	return &book, err
+}
+
+func (r *holdRepository) WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error) {
+	return r.commonRepo.WithTransaction(ctx, fn)
+}
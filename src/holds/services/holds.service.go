@@ -0,0 +1,306 @@
+package holdService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"fiber-app/src/auth"
+	"fiber-app/src/common"
+	copyService "fiber-app/src/copies/services"
+	"fiber-app/src/holds/dtos"
+	"fiber-app/src/holds/repository"
+	"fiber-app/src/logging"
+	"fiber-app/src/models"
+	"fiber-app/src/scheduler"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// PickupWindow is how long a copy stays set aside for a ready hold.
+	PickupWindow   = 3 * 24 * time.Hour
+	expireInterval = time.Minute
+)
+
+// openStatuses are the statuses of a hold that still has a claim on a copy.
+var openStatuses = bson.A{models.HoldStatusWaiting, models.HoldStatusReady}
+
+type HoldService struct {
+	repo   repository.HoldRepository
+	copies *copyService.CopyService
+}
+
+var logger = logging.For("holds")
+
+// NewHoldService initializes the repository and returns a new HoldService instance.
+func NewHoldService() *HoldService {
+	repo := repository.NewHoldRepository(common.GetDBCollection("holds"), common.GetDBCollection("books"))
+	return &HoldService{repo: repo, copies: copyService.NewCopyService()}
+}
+
+// RegisterTasks schedules the expiry of holds that were not picked up in time.
+func (s *HoldService) RegisterTasks() {
+	scheduler.Register("holds.expire", expireInterval, s.ExpireHolds)
+}
+
+// RegisterReleaser makes copies that are added or put back as available go to
+// waiting readers first.
+func (s *HoldService) RegisterReleaser() {
+	copyService.RegisterReleaser(func(ctx context.Context, copyID primitive.ObjectID, bookID primitive.ObjectID, from string) error {
+		_, err := s.ReleaseCopy(ctx, copyID, bookID, from)
+		return err
+	})
+}
+
+// GetHolds lists holds matching filter. Members only see their own holds. Waiting
+// holds carry their position in the book's queue.
+func (s *HoldService) GetHolds(ctx context.Context, user *auth.User, filter *dtos.HoldFilter) ([]models.Hold, error) {
+	query := bson.M{}
+	if filter.UserID != "" {
+		query["userId"] = filter.UserID
+	}
+	if !user.IsStaff() {
+		query["userId"] = user.ID
+	}
+	if filter.BookID != "" {
+		bookID, err := primitive.ObjectIDFromHex(filter.BookID)
+		if err != nil {
+			return nil, utils.Validation("invalid bookId")
+		}
+		query["bookId"] = bookID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	holds, err := s.repo.GetHolds(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range holds {
+		if err := s.setPosition(ctx, &holds[i]); err != nil {
+			return nil, err
+		}
+	}
+	return holds, nil
+}
+
+// GetHold returns a hold the user is allowed to see.
+func (s *HoldService) GetHold(ctx context.Context, user *auth.User, id string) (*models.Hold, error) {
+	hold, err := s.repo.GetHoldByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsStaff() && hold.UserID != user.ID {
+		return nil, utils.Forbidden("hold belongs to another user")
+	}
+	if err := s.setPosition(ctx, hold); err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// PlaceHold puts the user, or dto.UserID when staff place it, at the end of the
+// book's queue. Holds are only for books whose copies are all out; a reader can
+// hold a book once at a time.
+func (s *HoldService) PlaceHold(ctx context.Context, user *auth.User, dto *dtos.CreateDTO) (*models.Hold, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+
+	userID := user.ID
+	if dto.UserID != "" && dto.UserID != user.ID {
+		if !user.IsStaff() {
+			return nil, utils.Forbidden("only staff can place holds for another user")
+		}
+		userID = dto.UserID
+	}
+	bookID, _ := primitive.ObjectIDFromHex(dto.BookID)
+
+	hold, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		book, err := s.repo.GetBook(sessCtx, bookID)
+		if err != nil {
+			return nil, err
+		}
+		if book.CopiesTotal == 0 {
+			return nil, utils.Conflict("no_copies", "the library has no copies of this book")
+		}
+		if book.CopiesAvailable > 0 {
+			return nil, utils.Conflict("copies_available", "a copy is available, check it out instead")
+		}
+
+		exists, err := s.repo.HoldExists(sessCtx, bson.M{"bookId": bookID, "userId": userID, "status": bson.M{"$in": openStatuses}})
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, utils.Conflict("hold_exists", "there already is a hold on this book")
+		}
+
+		now := time.Now().UTC()
+		hold := &models.Hold{
+			ID:        primitive.NewObjectID(),
+			BookID:    bookID,
+			UserID:    userID,
+			Status:    models.HoldStatusWaiting,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if _, err := s.repo.CreateHold(sessCtx, hold); err != nil {
+			return nil, err
+		}
+		return hold, nil
+	})
+	// The unique index on open holds catches a concurrent request the check above
+	// missed.
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, utils.Conflict("hold_exists", "there already is a hold on this book")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	created := hold.(*models.Hold)
+	if err := s.setPosition(ctx, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// CancelHold withdraws a waiting or ready hold. The copy set aside for a ready hold
+// goes to the next reader in the queue.
+func (s *HoldService) CancelHold(ctx context.Context, user *auth.User, id string) (*models.Hold, error) {
+	current, err := s.GetHold(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return s.closeHold(sessCtx, bson.M{"_id": current.ID, "status": bson.M{"$in": openStatuses}}, models.HoldStatusCancelled)
+	})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, utils.Conflict("hold_closed", "hold is already "+current.Status)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hold.(*models.Hold), nil
+}
+
+// ExpireHolds closes ready holds whose pickup deadline has passed and passes their
+// copies on. Each hold is handled in its own transaction, and only if it is still
+// ready, so instances running this at the same time do not step on each other. A
+// hold that cannot be closed is logged and skipped so the rest still expire; the run
+// then reports how many failed.
+func (s *HoldService) ExpireHolds(ctx context.Context) error {
+	now := time.Now().UTC()
+	expired, err := s.repo.GetHolds(ctx, bson.M{"status": models.HoldStatusReady, "pickupBy": bson.M{"$lt": now}})
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, hold := range expired {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			filter := bson.M{"_id": hold.ID, "status": models.HoldStatusReady, "pickupBy": bson.M{"$lt": now}}
+			return s.closeHold(sessCtx, filter, models.HoldStatusExpired)
+		})
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.ErrorContext(ctx, "expiring hold", "hold_id", hold.ID.Hex(), logging.Err(err))
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("expiring holds: %d of %d failed", failed, len(expired))
+	}
+	return nil
+}
+
+// ReleaseCopy hands a copy coming back from status from to the next reader waiting
+// for its book, or puts it back on the shelf when nobody is. It returns the hold the
+// copy was assigned to, if any. Run it inside the caller's transaction.
+func (s *HoldService) ReleaseCopy(ctx context.Context, copyID primitive.ObjectID, bookID primitive.ObjectID, from string) (*models.Hold, error) {
+	next, err := s.repo.AssignNext(ctx, bookID, copyID, time.Now().UTC().Add(PickupWindow))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		_, err := s.copies.MoveCopy(ctx, bson.M{"_id": copyID}, from, models.CopyStatusAvailable)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.copies.MoveCopy(ctx, bson.M{"_id": copyID}, from, models.CopyStatusOnHold); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// FulfillHold closes the ready hold that copyID is set aside for, provided it
+// belongs to userID. Run it inside the checkout's transaction.
+func (s *HoldService) FulfillHold(ctx context.Context, copyID primitive.ObjectID, userID string) (*models.Hold, error) {
+	now := time.Now().UTC()
+	hold, err := s.repo.UpdateHold(ctx,
+		bson.M{"copyId": copyID, "userId": userID, "status": models.HoldStatusReady},
+		bson.M{"status": models.HoldStatusFulfilled, "closedAt": now, "updatedAt": now},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, utils.Conflict("copy_unavailable", "copy is on hold for another reader")
+	}
+	return hold, err
+}
+
+// FulfillWaiting closes the user's waiting hold on a book they just checked out
+// some other way, so they do not keep a place in the queue.
+func (s *HoldService) FulfillWaiting(ctx context.Context, bookID primitive.ObjectID, userID string) error {
+	now := time.Now().UTC()
+	_, err := s.repo.UpdateHold(ctx,
+		bson.M{"bookId": bookID, "userId": userID, "status": models.HoldStatusWaiting},
+		bson.M{"status": models.HoldStatusFulfilled, "closedAt": now, "updatedAt": now},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	return err
+}
+
+// HasWaitingHolds reports whether anyone is queued for the book.
+func (s *HoldService) HasWaitingHolds(ctx context.Context, bookID primitive.ObjectID) (bool, error) {
+	return s.repo.HoldExists(ctx, bson.M{"bookId": bookID, "status": models.HoldStatusWaiting})
+}
+
+// closeHold moves the hold matching filter to status and releases the copy it was
+// holding, if any.
+func (s *HoldService) closeHold(ctx context.Context, filter bson.M, status string) (*models.Hold, error) {
+	now := time.Now().UTC()
+	hold, err := s.repo.UpdateHold(ctx, filter, bson.M{"status": status, "closedAt": now, "updatedAt": now})
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.CopyID != nil {
+		if _, err := s.ReleaseCopy(ctx, *hold.CopyID, hold.BookID, models.CopyStatusOnHold); err != nil {
+			return nil, err
+		}
+	}
+	return hold, nil
+}
+
+func (s *HoldService) setPosition(ctx context.Context, hold *models.Hold) error {
+	if hold.Status != models.HoldStatusWaiting {
+		return nil
+	}
+	ahead, err := s.repo.CountAhead(ctx, hold)
+	if err != nil {
+		return err
+	}
+	hold.Position = int(ahead) + 1
+	return nil
+}
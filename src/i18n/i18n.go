@@ -0,0 +1,108 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/bn"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/text/language"
+)
+
+// Default is the locale used when a request accepts none of the supported ones.
+const Default = "en"
+
+// catalogs holds one flat JSON object of key to message per locale, named after the
+// locale. Messages take positional {0}, {1}... parameters.
+//
+//go:embed locales/*.json
+var catalogs embed.FS
+
+// placeholder matches a positional parameter in a message.
+var placeholder = regexp.MustCompile(`\{(\d+)\}`)
+
+// arity holds, per locale and key, how many parameters the message takes. The
+// translator panics when given fewer, so Lookup pads them.
+var arity = map[string]map[string]int{}
+
+var universal = newUniversalTranslator(en.New(), es.New(), bn.New())
+
+func newUniversalTranslator(supported ...locales.Translator) *ut.UniversalTranslator {
+	uni := ut.New(supported[0], supported...)
+	for _, locale := range supported {
+		trans, _ := uni.GetTranslator(locale.Locale())
+		if err := loadCatalog(trans); err != nil {
+			panic(err)
+		}
+	}
+	return uni
+}
+
+func loadCatalog(trans ut.Translator) error {
+	data, err := catalogs.ReadFile("locales/" + trans.Locale() + ".json")
+	if err != nil {
+		return err
+	}
+	var messages map[string]string
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("i18n: %s catalog: %w", trans.Locale(), err)
+	}
+	counts := make(map[string]int, len(messages))
+	for key, text := range messages {
+		if err := trans.Add(key, text, false); err != nil {
+			return fmt.Errorf("i18n: %s catalog: %q: %w", trans.Locale(), key, err)
+		}
+		counts[key] = 0
+		for _, match := range placeholder.FindAllStringSubmatch(text, -1) {
+			n, _ := strconv.Atoi(match[1])
+			counts[key] = max(counts[key], n+1)
+		}
+	}
+	arity[trans.Locale()] = counts
+	return nil
+}
+
+// Translator returns the translator of locale, or the default one when locale is
+// not supported.
+func Translator(locale string) ut.Translator {
+	trans, _ := universal.FindTranslator(locale)
+	return trans
+}
+
+// FromRequest returns the translator of the supported locale the request's
+// Accept-Language header prefers most, by quality value.
+func FromRequest(c *fiber.Ctx) ut.Translator {
+	tags, _, _ := language.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	preferred := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		base, _ := tag.Base()
+		preferred = append(preferred, base.String())
+	}
+	trans, _ := universal.FindTranslator(preferred...)
+	return trans
+}
+
+// Lookup renders the message of key in trans's locale, falling back to the default
+// locale's catalog. ok is false when neither has the key.
+func Lookup(trans ut.Translator, key string, params ...string) (string, bool) {
+	for _, t := range []ut.Translator{trans, universal.GetFallback()} {
+		n, ok := arity[t.Locale()][key]
+		if !ok {
+			continue
+		}
+		for len(params) < n {
+			params = append(params, "")
+		}
+		if message, err := t.T(key, params...); err == nil {
+			return message, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,83 @@
+package jobsController
+
+import (
+	"errors"
+	"fmt"
+
+	"fiber-app/src/auth"
+	"fiber-app/src/jobs/dtos"
+	jobService "fiber-app/src/jobs/services"
+	"fiber-app/src/models"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type JobController struct {
+	jobService *jobService.JobService
+}
+
+func NewJobController() *JobController {
+	return &JobController{
+		jobService: jobService.NewJobService(),
+	}
+}
+
+func (jc *JobController) GetJob(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.Validation("id is required")
+	}
+
+	job, err := jc.jobService.GetJob(c.UserContext(), auth.CurrentUser(c), id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return utils.NotFound("job not found")
+		}
+		return err
+	}
+
+	res := dtos.JobResponse{Job: job}
+	if job.Status == models.JobStatusSucceeded && job.ResultFileID != nil {
+		res.DownloadURL = "/jobs/" + job.ID.Hex() + "/download"
+	}
+
+	return c.Status(200).JSON(fiber.Map{"data": res})
+}
+
+// DownloadJobResult streams the file produced by a finished job to the user who
+// queued it, or to staff.
+func (jc *JobController) DownloadJobResult(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.Validation("id is required")
+	}
+
+	job, err := jc.jobService.GetJob(c.UserContext(), auth.CurrentUser(c), id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return utils.NotFound("job not found")
+		}
+		return err
+	}
+
+	stream, err := jc.jobService.OpenResult(job)
+	if err != nil {
+		return utils.NotFound("job has no downloadable result: " + err.Error())
+	}
+
+	file := stream.GetFile()
+	contentType := fiber.MIMEOctetStream
+	if value, err := bson.Raw(file.Metadata).LookupErr("contentType"); err == nil {
+		if s, ok := value.StringValueOK(); ok {
+			contentType = s
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, file.Name))
+	// SendStream closes the download stream once the body has been written.
+	return c.SendStream(stream, int(file.Length))
+}
@@ -0,0 +1,25 @@
+package dtos
+
+import "fiber-app/src/models"
+
+// JobResponse is a job as reported to clients, with a link to its result when there is one.
+type JobResponse struct {
+	*models.Job
+	DownloadURL string `json:"downloadUrl,omitempty"`
+}
+
+// JobAccepted is returned when a job has been queued.
+type JobAccepted struct {
+	JobID     string `json:"jobId"`
+	Status    string `json:"status"`
+	StatusURL string `json:"statusUrl"`
+}
+
+// NewJobAccepted builds the 202 body for a freshly queued job.
+func NewJobAccepted(job *models.Job) JobAccepted {
+	return JobAccepted{
+		JobID:     job.ID.Hex(),
+		Status:    job.Status,
+		StatusURL: "/jobs/" + job.ID.Hex(),
+	}
+}
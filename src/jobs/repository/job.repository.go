@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type JobRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	CreateJob(ctx context.Context, job *models.Job) (*mongo.InsertOneResult, error)
+	GetJobByID(ctx context.Context, id string) (*models.Job, error)
+	ClaimNextJob(ctx context.Context, types []string, owner string, lease time.Duration, maxAttempts int) (*models.Job, error)
+	RenewLease(ctx context.Context, id primitive.ObjectID, owner string, lease time.Duration) (bool, error)
+	UpdateLeasedJob(ctx context.Context, id primitive.ObjectID, owner string, set bson.M) (bool, error)
+	FinishJob(ctx context.Context, id primitive.ObjectID, owner string, set bson.M) (bool, error)
+	ReleaseJob(ctx context.Context, id primitive.ObjectID, owner string) (bool, error)
+	FailAbandonedJobs(ctx context.Context, maxAttempts int) ([]models.Job, error)
+}
+
+type jobRepository struct {
+	commonRepo *common.CommonRepository
+}
+
+func NewJobRepository(collection *mongo.Collection) JobRepository {
+	return &jobRepository{commonRepo: common.NewCommonRepository(collection)}
+}
+
+func (r *jobRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.commonRepo.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: 1}},
+	})
+	return err
+}
+
+func (r *jobRepository) CreateJob(ctx context.Context, job *models.Job) (*mongo.InsertOneResult, error) {
+	return r.commonRepo.InsertOne(ctx, job)
+}
+
+func (r *jobRepository) GetJobByID(ctx context.Context, id string) (*models.Job, error) {
+	objectID, err := r.commonRepo.ConvertID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var job models.Job
+	err = r.commonRepo.FindOne(ctx, bson.M{"_id": objectID}, &job)
+	return &job, err
+}
+
+// ClaimNextJob atomically leases the oldest job that is queued, or whose previous
+// lease has expired, to owner. It returns nil when there is nothing to run.
+func (r *jobRepository) ClaimNextJob(ctx context.Context, types []string, owner string, lease time.Duration, maxAttempts int) (*models.Job, error) {
+	now := time.Now().UTC()
+	expires := now.Add(lease)
+
+	filter := bson.M{
+		"type":     bson.M{"$in": types},
+		"attempts": bson.M{"$lt": maxAttempts},
+		"$or": bson.A{
+			bson.M{"status": models.JobStatusQueued},
+			bson.M{"status": models.JobStatusRunning, "leaseExpiresAt": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":         models.JobStatusRunning,
+			"leaseOwner":     owner,
+			"leaseExpiresAt": expires,
+			"startedAt":      now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"createdAt": 1}).
+		SetReturnDocument(options.After)
+
+	res, err := r.commonRepo.FindAndModify(ctx, filter, update, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var job models.Job
+	if err := res.Decode(&job); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// RenewLease extends the lease if owner still holds it. It reports false once the
+// lease has been lost to another worker.
+func (r *jobRepository) RenewLease(ctx context.Context, id primitive.ObjectID, owner string, lease time.Duration) (bool, error) {
+	return r.UpdateLeasedJob(ctx, id, owner, bson.M{"leaseExpiresAt": time.Now().UTC().Add(lease)})
+}
+
+// UpdateLeasedJob sets fields on a running job, but only while owner holds its lease.
+func (r *jobRepository) UpdateLeasedJob(ctx context.Context, id primitive.ObjectID, owner string, set bson.M) (bool, error) {
+	res, err := r.commonRepo.UpdateOne(ctx, bson.M{"_id": id, "leaseOwner": owner, "status": models.JobStatusRunning}, set)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// FinishJob records the final state of a job and releases its lease.
+func (r *jobRepository) FinishJob(ctx context.Context, id primitive.ObjectID, owner string, set bson.M) (bool, error) {
+	set["finishedAt"] = time.Now().UTC()
+	res, err := r.commonRepo.UpdateOneRaw(ctx,
+		bson.M{"_id": id, "leaseOwner": owner, "status": models.JobStatusRunning},
+		bson.M{"$set": set, "$unset": bson.M{"leaseOwner": "", "leaseExpiresAt": ""}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// ReleaseJob puts a running job back in the queue if owner still holds its lease. The
+// attempt is not counted, since the job did not fail: its worker is shutting down.
+func (r *jobRepository) ReleaseJob(ctx context.Context, id primitive.ObjectID, owner string) (bool, error) {
+	res, err := r.commonRepo.UpdateOneRaw(ctx,
+		bson.M{"_id": id, "leaseOwner": owner, "status": models.JobStatusRunning},
+		bson.M{
+			"$set":   bson.M{"status": models.JobStatusQueued},
+			"$unset": bson.M{"leaseOwner": "", "leaseExpiresAt": ""},
+			"$inc":   bson.M{"attempts": -1},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// FailAbandonedJobs marks jobs whose lease expired after their last allowed attempt
+// as failed, so they do not stay "running" forever. It returns the jobs it failed.
+func (r *jobRepository) FailAbandonedJobs(ctx context.Context, maxAttempts int) ([]models.Job, error) {
+	now := time.Now().UTC()
+	filter := bson.M{"status": models.JobStatusRunning, "attempts": bson.M{"$gte": maxAttempts}, "leaseExpiresAt": bson.M{"$lt": now}}
+
+	var abandoned []models.Job
+	if err := r.commonRepo.FindAll(ctx, filter, &abandoned); err != nil {
+		return nil, err
+	}
+
+	failed := abandoned[:0]
+	for _, job := range abandoned {
+		// Only fail the job if no other instance failed or reclaimed it meanwhile.
+		res, err := r.commonRepo.UpdateOne(ctx,
+			bson.M{"$and": bson.A{bson.M{"_id": job.ID}, filter}},
+			bson.M{"status": models.JobStatusFailed, "error": "job was abandoned by its worker too many times", "finishedAt": now},
+		)
+		if err != nil {
+			return failed, err
+		}
+		if res.ModifiedCount == 1 {
+			failed = append(failed, job)
+		}
+	}
+	return failed, nil
+}
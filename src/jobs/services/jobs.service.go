@@ -0,0 +1,215 @@
+package jobService
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"fiber-app/src/auth"
+	"fiber-app/src/common"
+	"fiber-app/src/jobs/repository"
+	"fiber-app/src/logging"
+	"fiber-app/src/models"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// jobFilesBucket is the GridFS bucket holding job uploads and results.
+const jobFilesBucket = "job_files"
+
+// progressInterval limits how often a running job writes its progress to the database.
+const progressInterval = 2 * time.Second
+
+// Handler runs one job. Returning an error marks the job as failed.
+type Handler func(ctx context.Context, run *Run) error
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[string]Handler{}
+)
+
+var logger = logging.For("jobs")
+
+// RegisterHandler makes jobs of the given type runnable by this instance's worker pool.
+// Modules register their handlers while routes are being set up, before Start.
+func RegisterHandler(jobType string, h Handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[jobType] = h
+}
+
+func getHandler(jobType string) (Handler, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	h, ok := handlers[jobType]
+	return h, ok
+}
+
+func registeredTypes() []string {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	types := make([]string, 0, len(handlers))
+	for t := range handlers {
+		types = append(types, t)
+	}
+	return types
+}
+
+type JobService struct {
+	repo repository.JobRepository
+}
+
+// NewJobService initializes the repository and returns a new JobService instance.
+func NewJobService() *JobService {
+	dbCollection := common.GetDBCollection("jobs")
+	repo := repository.NewJobRepository(dbCollection)
+	return &JobService{repo: repo}
+}
+
+// Enqueue stores the optional input in GridFS and queues a job for the worker pool.
+func (s *JobService) Enqueue(ctx context.Context, user *auth.User, jobType string, params map[string]string, input io.Reader, filename string) (*models.Job, error) {
+	job := models.Job{
+		Type:      jobType,
+		Status:    models.JobStatusQueued,
+		Params:    params,
+		CreatedBy: user.ID,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if input != nil {
+		bucket, err := common.GetGridFSBucket(jobFilesBucket)
+		if err != nil {
+			return nil, err
+		}
+		fileID, err := bucket.UploadFromStream(filename, input, options.GridFSUpload().SetMetadata(bson.M{"jobType": jobType, "kind": "input"}))
+		if err != nil {
+			return nil, err
+		}
+		job.InputFileID = &fileID
+	}
+
+	res, err := s.repo.CreateJob(ctx, &job)
+	if err != nil {
+		if job.InputFileID != nil {
+			s.deleteFile(*job.InputFileID)
+		}
+		return nil, err
+	}
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		job.ID = oid
+	} else {
+		return nil, errors.New("failed to retrieve inserted ID")
+	}
+
+	return &job, nil
+}
+
+// GetJob returns a job the user is allowed to see: one they queued, or any as staff.
+func (s *JobService) GetJob(ctx context.Context, user *auth.User, id string) (*models.Job, error) {
+	job, err := s.repo.GetJobByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsStaff() && job.CreatedBy != user.ID {
+		return nil, utils.Forbidden("job belongs to another user")
+	}
+	return job, nil
+}
+
+// OpenResult opens the result file of a finished job for reading.
+func (s *JobService) OpenResult(job *models.Job) (*gridfs.DownloadStream, error) {
+	if job.ResultFileID == nil || job.Status != models.JobStatusSucceeded {
+		return nil, utils.NewError(http.StatusNotFound, "no_result", "job has no downloadable result")
+	}
+	bucket, err := common.GetGridFSBucket(jobFilesBucket)
+	if err != nil {
+		return nil, err
+	}
+	return bucket.OpenDownloadStream(*job.ResultFileID)
+}
+
+func (s *JobService) deleteFile(id primitive.ObjectID) {
+	bucket, err := common.GetGridFSBucket(jobFilesBucket)
+	if err == nil {
+		err = bucket.Delete(id)
+	}
+	if err != nil {
+		logger.Error("deleting job file", "file_id", id.Hex(), logging.Err(err))
+	}
+}
+
+// Run is the handle a Handler uses to read its input and report progress and results.
+type Run struct {
+	Job *models.Job
+
+	svc          *JobService
+	owner        string
+	processed    int64
+	counts       map[string]int64
+	errors       []models.JobError
+	resultFileID *primitive.ObjectID
+	lastFlush    time.Time
+}
+
+// Input opens the file uploaded with the job.
+func (r *Run) Input() (io.ReadCloser, error) {
+	if r.Job.InputFileID == nil {
+		return nil, utils.BadRequest("no_input", "job has no input file")
+	}
+	bucket, err := common.GetGridFSBucket(jobFilesBucket)
+	if err != nil {
+		return nil, err
+	}
+	return bucket.OpenDownloadStream(*r.Job.InputFileID)
+}
+
+// CreateResult opens a GridFS upload stream for the job's downloadable result. The
+// caller must close it for the result to be saved.
+func (r *Run) CreateResult(filename, contentType string) (*gridfs.UploadStream, error) {
+	bucket, err := common.GetGridFSBucket(jobFilesBucket)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := bucket.OpenUploadStream(filename, options.GridFSUpload().SetMetadata(bson.M{"jobType": r.Job.Type, "kind": "result", "contentType": contentType}))
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := stream.FileID.(primitive.ObjectID); ok {
+		r.resultFileID = &id
+	}
+	return stream, nil
+}
+
+// Report records the job's current progress. It is written to the database at most
+// every few seconds; the final values are always saved when the job finishes.
+func (r *Run) Report(ctx context.Context, processed int64, counts map[string]int64, errs []models.JobError) {
+	r.processed = processed
+	r.counts = counts
+	r.errors = errs
+
+	if !r.Due() {
+		return
+	}
+	r.lastFlush = time.Now()
+	if _, err := r.svc.repo.UpdateLeasedJob(ctx, r.Job.ID, r.owner, r.progressFields()); err != nil {
+		logger.ErrorContext(ctx, "saving job progress", "job_id", r.Job.ID.Hex(), logging.Err(err))
+	}
+}
+
+// Due reports whether the next Report will be written to the database. Callers whose
+// progress is costly to build can skip the reports in between, as long as they still
+// report the final values.
+func (r *Run) Due() bool {
+	return time.Since(r.lastFlush) >= progressInterval
+}
+
+func (r *Run) progressFields() bson.M {
+	return bson.M{"processed": r.processed, "counts": r.counts, "errors": r.errors}
+}
@@ -0,0 +1,225 @@
+package jobService
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"fiber-app/src/logging"
+	"fiber-app/src/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	leaseDuration   = 30 * time.Second
+	pollInterval    = time.Second
+	maxJobAttempts  = 3
+	finishTimeout   = 10 * time.Second
+	sweepEveryPolls = 30
+)
+
+// WorkerPool runs queued jobs on a fixed number of goroutines. Jobs are leased in
+// the database, so several instances can share the same queue safely.
+type WorkerPool struct {
+	svc   *JobService
+	size  int
+	owner string
+
+	stop      chan struct{}
+	jobCtx    context.Context
+	cancelJob context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewWorkerPool creates a pool with size workers. It does nothing until Start.
+func NewWorkerPool(size int) *WorkerPool {
+	if size < 1 {
+		size = 1
+	}
+	host, _ := os.Hostname()
+	jobCtx, cancel := context.WithCancel(context.Background())
+
+	return &WorkerPool{
+		svc:       NewJobService(),
+		size:      size,
+		owner:     fmt.Sprintf("%s-%d-%s", host, os.Getpid(), primitive.NewObjectID().Hex()),
+		stop:      make(chan struct{}),
+		jobCtx:    jobCtx,
+		cancelJob: cancel,
+	}
+}
+
+// Start launches the workers. Handlers must be registered before it is called.
+func (p *WorkerPool) Start() error {
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+	if err := p.svc.repo.EnsureIndexes(ctx); err != nil {
+		return err
+	}
+
+	for i := 0; i < p.size; i++ {
+		p.wg.Add(1)
+		go p.work(i)
+	}
+	return nil
+}
+
+// Stop stops claiming new jobs and waits for running ones to finish. If ctx expires
+// first, running jobs are cancelled and released so another instance can retry them.
+func (p *WorkerPool) Stop(ctx context.Context) error {
+	close(p.stop)
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancelJob()
+		return nil
+	case <-ctx.Done():
+		p.cancelJob()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (p *WorkerPool) work(worker int) {
+	defer p.wg.Done()
+
+	polls := 0
+	for {
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+
+		// Only one worker needs to clean up jobs whose workers died for good.
+		if worker == 0 && polls%sweepEveryPolls == 0 {
+			failed, err := p.svc.repo.FailAbandonedJobs(p.jobCtx, maxJobAttempts)
+			if err != nil {
+				logger.Error("failing abandoned jobs", logging.Err(err))
+			}
+			// Failed jobs are not retried, so their input is no longer needed.
+			for _, job := range failed {
+				if job.InputFileID != nil {
+					p.svc.deleteFile(*job.InputFileID)
+				}
+			}
+		}
+		polls++
+
+		job, err := p.svc.repo.ClaimNextJob(p.jobCtx, registeredTypes(), p.owner, leaseDuration, maxJobAttempts)
+		if err != nil {
+			logger.Error("claiming job", logging.Err(err))
+		}
+		if job != nil {
+			p.run(job)
+			continue
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// run executes a leased job, renewing the lease until the handler returns.
+func (p *WorkerPool) run(job *models.Job) {
+	ctx, cancel := context.WithCancel(p.jobCtx)
+	defer cancel()
+
+	go p.heartbeat(ctx, cancel, job.ID)
+
+	run := &Run{Job: job, svc: p.svc, owner: p.owner, lastFlush: time.Now()}
+	err := p.invoke(ctx, job, run)
+
+	// The finish writes below use their own context because the job's may be cancelled.
+	finishCtx, finishCancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer finishCancel()
+
+	if err != nil && p.jobCtx.Err() != nil {
+		// Interrupted by shutdown: hand the job back instead of failing it, without
+		// using up one of its attempts.
+		if _, releaseErr := p.svc.repo.ReleaseJob(finishCtx, job.ID, p.owner); releaseErr != nil {
+			logger.Error("releasing job lease", "job_id", job.ID.Hex(), logging.Err(releaseErr))
+		}
+		if run.resultFileID != nil {
+			p.svc.deleteFile(*run.resultFileID)
+		}
+		return
+	}
+
+	set := run.progressFields()
+	if run.resultFileID != nil {
+		set["resultFileId"] = run.resultFileID
+	}
+	if err != nil {
+		set["status"] = models.JobStatusFailed
+		set["error"] = err.Error()
+	} else {
+		set["status"] = models.JobStatusSucceeded
+	}
+
+	owned, finishErr := p.svc.repo.FinishJob(finishCtx, job.ID, p.owner, set)
+	if finishErr != nil {
+		logger.Error("finishing job", "job_id", job.ID.Hex(), logging.Err(finishErr))
+		return
+	}
+	if !owned {
+		// Another worker took over after our lease lapsed; its result wins.
+		if run.resultFileID != nil {
+			p.svc.deleteFile(*run.resultFileID)
+		}
+		return
+	}
+	// Succeeded or failed for good, the job is done with its input either way.
+	if job.InputFileID != nil {
+		p.svc.deleteFile(*job.InputFileID)
+	}
+}
+
+func (p *WorkerPool) invoke(ctx context.Context, job *models.Job, run *Run) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	handler, ok := getHandler(job.Type)
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+	return handler(ctx, run)
+}
+
+func (p *WorkerPool) heartbeat(ctx context.Context, cancel context.CancelFunc, id primitive.ObjectID) {
+	ticker := time.NewTicker(leaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			owned, err := p.svc.repo.RenewLease(ctx, id, p.owner, leaseDuration)
+			if err != nil {
+				logger.Error("renewing job lease", "job_id", id.Hex(), logging.Err(err))
+				continue
+			}
+			if !owned {
+				logger.Warn("lost job lease", "job_id", id.Hex())
+				cancel()
+				return
+			}
+		}
+	}
+}
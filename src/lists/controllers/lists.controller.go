@@ -0,0 +1,139 @@
+package listsController
+
+import (
+	"fiber-app/src/auth"
+	"fiber-app/src/lists/dtos"
+	listService "fiber-app/src/lists/services"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ListController struct {
+	listService *listService.ListService
+}
+
+func NewListController() *ListController {
+	return &ListController{
+		listService: listService.NewListService(),
+	}
+}
+
+// GetMyLists returns the caller's shelves and custom lists.
+func (lc *ListController) GetMyLists(c *fiber.Ctx) error {
+	lists, err := lc.listService.GetMyLists(c.UserContext(), auth.CurrentUser(c))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": lists})
+}
+
+func (lc *ListController) GetCollections(c *fiber.Ctx) error {
+	lists, err := lc.listService.GetCollections(c.UserContext())
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": lists})
+}
+
+func (lc *ListController) GetList(c *fiber.Ctx) error {
+	list, err := lc.listService.GetList(c.UserContext(), auth.CurrentUser(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": list})
+}
+
+// GetSharedList returns the list behind the share token in the :token param.
+func (lc *ListController) GetSharedList(c *fiber.Ctx) error {
+	list, err := lc.listService.GetSharedList(c.UserContext(), c.Params("token"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": list})
+}
+
+func (lc *ListController) CreateList(c *fiber.Ctx) error {
+	dto := new(dtos.CreateDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	list, err := lc.listService.CreateList(c.UserContext(), auth.CurrentUser(c), dto)
+	if err != nil {
+		return err
+	}
+
+	c.Location("/lists/" + list.ID.Hex())
+	return c.Status(201).JSON(fiber.Map{"result": list})
+}
+
+func (lc *ListController) CreateCollection(c *fiber.Ctx) error {
+	dto := new(dtos.CreateCollectionDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	list, err := lc.listService.CreateCollection(c.UserContext(), auth.CurrentUser(c), dto)
+	if err != nil {
+		return err
+	}
+
+	c.Location("/lists/" + list.ID.Hex())
+	return c.Status(201).JSON(fiber.Map{"result": list})
+}
+
+func (lc *ListController) UpdateList(c *fiber.Ctx) error {
+	dto := new(dtos.UpdateDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	list, err := lc.listService.UpdateList(c.UserContext(), auth.CurrentUser(c), c.Params("id"), dto)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": list})
+}
+
+func (lc *ListController) DeleteList(c *fiber.Ctx) error {
+	result, err := lc.listService.DeleteList(c.UserContext(), auth.CurrentUser(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": result})
+}
+
+func (lc *ListController) AddBook(c *fiber.Ctx) error {
+	dto := new(dtos.AddBookDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	list, err := lc.listService.AddBook(c.UserContext(), auth.CurrentUser(c), c.Params("id"), dto)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": list})
+}
+
+func (lc *ListController) RemoveBook(c *fiber.Ctx) error {
+	list, err := lc.listService.RemoveBook(c.UserContext(), auth.CurrentUser(c), c.Params("id"), c.Params("bookId"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": list})
+}
+
+func (lc *ListController) ReorderBooks(c *fiber.Ctx) error {
+	dto := new(dtos.ReorderDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	list, err := lc.listService.ReorderBooks(c.UserContext(), auth.CurrentUser(c), c.Params("id"), dto)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": list})
+}
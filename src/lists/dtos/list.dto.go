@@ -0,0 +1,57 @@
+package dtos
+
+import "fiber-app/src/utils"
+
+// CreateDTO represents the structure for creating a custom list.
+type CreateDTO struct {
+	Name        string `json:"name" validate:"required,min=1,max=100"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=2000"`
+	Visibility  string `json:"visibility,omitempty" validate:"omitempty,oneof=private link public"`
+}
+
+// CreateCollectionDTO represents the structure for creating a curated collection.
+// Tag defaults to the slug of the name.
+type CreateCollectionDTO struct {
+	Name        string `json:"name" validate:"required,min=1,max=100"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=2000"`
+	Tag         string `json:"tag,omitempty" validate:"omitempty,max=50"`
+}
+
+// UpdateDTO represents the structure for updating a list. Collections are always
+// public, so Visibility does not apply to them.
+type UpdateDTO struct {
+	Name        string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=2000"`
+	Visibility  string `json:"visibility,omitempty" validate:"omitempty,oneof=private link public"`
+}
+
+// AddBookDTO is the body of POST /lists/:id/books.
+type AddBookDTO struct {
+	BookID string `json:"bookId" validate:"required,objectid"`
+}
+
+// ReorderDTO is the body of PUT /lists/:id/books. It must name exactly the books
+// already on the list, in their new order.
+type ReorderDTO struct {
+	BookIDs []string `json:"bookIds" validate:"required,dive,objectid"`
+}
+
+func (dto *CreateDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
+
+func (dto *CreateCollectionDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
+
+func (dto *UpdateDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
+
+func (dto *AddBookDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
+
+func (dto *ReorderDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ListRepository interface {
+	GetLists(ctx context.Context, filter interface{}) ([]models.List, error)
+	GetList(ctx context.Context, filter interface{}) (*models.List, error)
+	CreateList(ctx context.Context, list *models.List) (*mongo.InsertOneResult, error)
+	EnsureList(ctx context.Context, list *models.List) error
+	UpdateList(ctx context.Context, id primitive.ObjectID, set bson.M, unset bson.M) (*mongo.UpdateResult, error)
+	DeleteList(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error)
+	AddBook(ctx context.Context, id primitive.ObjectID, bookID primitive.ObjectID) (*mongo.UpdateResult, error)
+	RemoveBook(ctx context.Context, id primitive.ObjectID, bookID primitive.ObjectID) (*mongo.UpdateResult, error)
+	ReorderBooks(ctx context.Context, id primitive.ObjectID, current []primitive.ObjectID, ordered []primitive.ObjectID) (bool, error)
+	BookExists(ctx context.Context, bookID primitive.ObjectID) (bool, error)
+	TagBook(ctx context.Context, bookID primitive.ObjectID, tag string) error
+	UntagBooks(ctx context.Context, filter bson.M, tag string) error
+	RenameTag(ctx context.Context, from string, to string) error
+	WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error)
+}
+
+type listRepository struct {
+	commonRepo *common.CommonRepository
+	booksRepo  *common.CommonRepository
+}
+
+// NewListRepository takes the books collection as well because collections keep
+// their tag on every book they contain.
+func NewListRepository(collection *mongo.Collection, books *mongo.Collection) ListRepository {
+	return &listRepository{
+		commonRepo: common.NewCommonRepository(collection),
+		booksRepo:  common.NewCommonRepository(books),
+	}
+}
+
+func (r *listRepository) GetLists(ctx context.Context, filter interface{}) ([]models.List, error) {
+	lists := []models.List{}
+	err := r.commonRepo.FindAll(ctx, filter, &lists, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}))
+	return lists, err
+}
+
+func (r *listRepository) GetList(ctx context.Context, filter interface{}) (*models.List, error) {
+	var list models.List
+	err := r.commonRepo.FindOne(ctx, filter, &list)
+	return &list, err
+}
+
+func (r *listRepository) CreateList(ctx context.Context, list *models.List) (*mongo.InsertOneResult, error) {
+	return r.commonRepo.InsertOne(ctx, list)
+}
+
+// EnsureList creates the list unless its owner already has one with the same slug.
+func (r *listRepository) EnsureList(ctx context.Context, list *models.List) error {
+	_, err := r.commonRepo.UpdateOneRaw(ctx,
+		bson.M{"ownerId": list.OwnerID, "slug": list.Slug},
+		bson.M{"$setOnInsert": list},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		// Created by a concurrent request in between.
+		return nil
+	}
+	return err
+}
+
+func (r *listRepository) UpdateList(ctx context.Context, id primitive.ObjectID, set bson.M, unset bson.M) (*mongo.UpdateResult, error) {
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return r.commonRepo.UpdateOneRaw(ctx, bson.M{"_id": id}, update)
+}
+
+func (r *listRepository) DeleteList(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error) {
+	return r.commonRepo.DeleteOne(ctx, bson.M{"_id": id})
+}
+
+// AddBook appends a book to the list unless it is already on it.
+func (r *listRepository) AddBook(ctx context.Context, id primitive.ObjectID, bookID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	res, err := r.commonRepo.AddToSet(ctx, bson.M{"_id": id}, "bookIds", bookID)
+	if err != nil || res.ModifiedCount == 0 {
+		return res, err
+	}
+	_, err = r.commonRepo.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"updatedAt": time.Now().UTC()})
+	return res, err
+}
+
+func (r *listRepository) RemoveBook(ctx context.Context, id primitive.ObjectID, bookID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	return r.commonRepo.UpdateOneRaw(ctx, bson.M{"_id": id}, bson.M{
+		"$pull": bson.M{"bookIds": bookID},
+		"$set":  bson.M{"updatedAt": time.Now().UTC()},
+	})
+}
+
+// ReorderBooks replaces the list's books with ordered, provided they still are
+// current, and reports whether it did.
+func (r *listRepository) ReorderBooks(ctx context.Context, id primitive.ObjectID, current []primitive.ObjectID, ordered []primitive.ObjectID) (bool, error) {
+	res, err := r.commonRepo.UpdateOne(ctx,
+		bson.M{"_id": id, "bookIds": current},
+		bson.M{"bookIds": ordered, "updatedAt": time.Now().UTC()},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (r *listRepository) BookExists(ctx context.Context, bookID primitive.ObjectID) (bool, error) {
+	return r.booksRepo.Exists(ctx, bson.M{"_id": bookID, "deletedAt": bson.M{"$exists": false}})
+}
+
+func (r *listRepository) TagBook(ctx context.Context, bookID primitive.ObjectID, tag string) error {
+	_, err := r.booksRepo.AddToSet(ctx, bson.M{"_id": bookID}, "tags", tag)
+	return err
+}
+
+// UntagBooks removes tag from the books matching filter.
+func (r *listRepository) UntagBooks(ctx context.Context, filter bson.M, tag string) error {
+	_, err := r.booksRepo.UpdateManyRaw(ctx, filter, bson.M{"$pull": bson.M{"tags": tag}})
+	return err
+}
+
+// RenameTag replaces tag from with to on every book that has it.
+func (r *listRepository) RenameTag(ctx context.Context, from string, to string) error {
+	_, err := r.booksRepo.UpdateManyRaw(ctx, bson.M{"tags": from}, bson.M{"$set": bson.M{"tags.$": to}})
+	return err
+}
+
+func (r *listRepository) WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error) {
+	return r.commonRepo.WithTransaction(ctx, fn)
+}
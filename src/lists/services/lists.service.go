@@ -0,0 +1,403 @@
+package listService
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"fiber-app/src/auth"
+	bookRepository "fiber-app/src/books/repository"
+	"fiber-app/src/common"
+	"fiber-app/src/lists/dtos"
+	"fiber-app/src/lists/repository"
+	"fiber-app/src/models"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaultShelves are created for every user the first time they look at their lists.
+var defaultShelves = []struct{ Type, Name string }{
+	{models.ListTypeToRead, "To read"},
+	{models.ListTypeFavorites, "Favorites"},
+}
+
+type ListService struct {
+	repo  repository.ListRepository
+	books bookRepository.BookRepository
+}
+
+// NewListService initializes the repositories and returns a new ListService instance.
+func NewListService() *ListService {
+	books := common.GetDBCollection("books")
+	return &ListService{
+		repo:  repository.NewListRepository(common.GetDBCollection("lists"), books),
+		books: bookRepository.NewBookRepository(books),
+	}
+}
+
+// GetMyLists returns the user's shelves and custom lists, creating the default
+// shelves on first use.
+func (s *ListService) GetMyLists(ctx context.Context, user *auth.User) ([]models.List, error) {
+	now := time.Now().UTC()
+	for _, shelf := range defaultShelves {
+		list := &models.List{
+			OwnerID:    user.ID,
+			Type:       shelf.Type,
+			Name:       shelf.Name,
+			Slug:       utils.Slugify(shelf.Name),
+			Visibility: models.ListVisibilityPrivate,
+			BookIDs:    []primitive.ObjectID{},
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := s.repo.EnsureList(ctx, list); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.GetLists(ctx, bson.M{"ownerId": user.ID, "type": bson.M{"$ne": models.ListTypeCollection}})
+}
+
+// GetCollections returns every curated collection.
+func (s *ListService) GetCollections(ctx context.Context) ([]models.List, error) {
+	return s.repo.GetLists(ctx, bson.M{"type": models.ListTypeCollection})
+}
+
+// GetList returns a list with its books if the user may see it. user is nil for
+// anonymous callers, who only see public lists.
+func (s *ListService) GetList(ctx context.Context, user *auth.User, id string) (*models.List, error) {
+	list, err := s.getList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if list.Visibility != models.ListVisibilityPublic && (user == nil || !canEdit(user, list)) {
+		return nil, mongo.ErrNoDocuments
+	}
+	if user == nil || !canEdit(user, list) {
+		list.ShareToken = ""
+	}
+	return list, s.populateBooks(ctx, list)
+}
+
+// GetSharedList returns the list behind a share link.
+func (s *ListService) GetSharedList(ctx context.Context, token string) (*models.List, error) {
+	if token == "" {
+		return nil, mongo.ErrNoDocuments
+	}
+	list, err := s.repo.GetList(ctx, bson.M{"shareToken": token, "visibility": bson.M{"$ne": models.ListVisibilityPrivate}})
+	if err != nil {
+		return nil, err
+	}
+	list.ShareToken = ""
+	return list, s.populateBooks(ctx, list)
+}
+
+// CreateList adds a custom list for the user.
+func (s *ListService) CreateList(ctx context.Context, user *auth.User, dto *dtos.CreateDTO) (*models.List, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+
+	visibility := dto.Visibility
+	if visibility == "" {
+		visibility = models.ListVisibilityPrivate
+	}
+	list := newList(user.ID, models.ListTypeCustom, dto.Name, dto.Description, visibility)
+	if visibility == models.ListVisibilityLink {
+		token, err := newShareToken()
+		if err != nil {
+			return nil, err
+		}
+		list.ShareToken = token
+	}
+	return list, s.insertList(ctx, list)
+}
+
+// CreateCollection adds a public collection curated by staff. Its tag is put on
+// every book added to it, which is what GET /books?tag= filters on.
+func (s *ListService) CreateCollection(ctx context.Context, user *auth.User, dto *dtos.CreateCollectionDTO) (*models.List, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+
+	list := newList(user.ID, models.ListTypeCollection, dto.Name, dto.Description, models.ListVisibilityPublic)
+	list.Tag = utils.Slugify(dto.Tag)
+	if list.Tag == "" {
+		list.Tag = list.Slug
+	}
+	return list, s.insertList(ctx, list)
+}
+
+// UpdateList renames a list or changes its description or visibility. Making a
+// list shareable by link gives it a share token; making it private revokes it.
+func (s *ListService) UpdateList(ctx context.Context, user *auth.User, id string, dto *dtos.UpdateDTO) (*models.List, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+	list, err := s.getEditableList(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{"updatedAt": time.Now().UTC()}
+	unset := bson.M{}
+	if dto.Name != "" {
+		if isDefaultShelf(list) {
+			return nil, utils.Validation("default shelves cannot be renamed")
+		}
+		set["name"] = strings.TrimSpace(dto.Name)
+		set["slug"] = utils.Slugify(dto.Name)
+	}
+	if dto.Description != "" {
+		set["description"] = dto.Description
+	}
+	if dto.Visibility != "" && list.Type != models.ListTypeCollection {
+		set["visibility"] = dto.Visibility
+		switch {
+		case dto.Visibility == models.ListVisibilityPrivate:
+			unset["shareToken"] = ""
+		case list.ShareToken == "":
+			token, err := newShareToken()
+			if err != nil {
+				return nil, err
+			}
+			set["shareToken"] = token
+		}
+	}
+
+	if _, err := s.repo.UpdateList(ctx, list.ID, set, unset); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, utils.Conflict("list_exists", "you already have a list with this name")
+		}
+		return nil, err
+	}
+	return s.GetList(ctx, user, id)
+}
+
+// DeleteList removes a custom list or collection. A collection's tag is taken off
+// its books. Default shelves cannot be deleted.
+func (s *ListService) DeleteList(ctx context.Context, user *auth.User, id string) (*mongo.DeleteResult, error) {
+	list, err := s.getEditableList(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	if isDefaultShelf(list) {
+		return nil, utils.Validation("default shelves cannot be deleted")
+	}
+
+	res, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		if list.Tag != "" {
+			if err := s.repo.UntagBooks(sessCtx, bson.M{"tags": list.Tag}, list.Tag); err != nil {
+				return nil, err
+			}
+		}
+		return s.repo.DeleteList(sessCtx, list.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*mongo.DeleteResult), nil
+}
+
+// AddBook puts a book at the end of a list. Adding a book that is already on the
+// list changes nothing.
+func (s *ListService) AddBook(ctx context.Context, user *auth.User, id string, dto *dtos.AddBookDTO) (*models.List, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+	list, err := s.getEditableList(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	bookID, _ := primitive.ObjectIDFromHex(dto.BookID)
+
+	_, err = s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		exists, err := s.repo.BookExists(sessCtx, bookID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, mongo.ErrNoDocuments
+		}
+		if _, err := s.repo.AddBook(sessCtx, list.ID, bookID); err != nil {
+			return nil, err
+		}
+		if list.Tag != "" {
+			return nil, s.repo.TagBook(sessCtx, bookID, list.Tag)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetList(ctx, user, id)
+}
+
+// RemoveBook takes a book off a list.
+func (s *ListService) RemoveBook(ctx context.Context, user *auth.User, id string, bookID string) (*models.List, error) {
+	list, err := s.getEditableList(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	bookObjectID, err := primitive.ObjectIDFromHex(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		if _, err := s.repo.RemoveBook(sessCtx, list.ID, bookObjectID); err != nil {
+			return nil, err
+		}
+		if list.Tag != "" {
+			return nil, s.repo.UntagBooks(sessCtx, bson.M{"_id": bookObjectID}, list.Tag)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetList(ctx, user, id)
+}
+
+// ReorderBooks puts the list's books in the given order, which must contain each
+// of them exactly once.
+func (s *ListService) ReorderBooks(ctx context.Context, user *auth.User, id string, dto *dtos.ReorderDTO) (*models.List, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+	list, err := s.getEditableList(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	onList := make(map[primitive.ObjectID]bool, len(list.BookIDs))
+	for _, bookID := range list.BookIDs {
+		onList[bookID] = true
+	}
+	ordered := make([]primitive.ObjectID, 0, len(dto.BookIDs))
+	for _, raw := range dto.BookIDs {
+		bookID, _ := primitive.ObjectIDFromHex(raw)
+		if !onList[bookID] {
+			return nil, utils.Validation("bookIds must list every book on the list exactly once")
+		}
+		delete(onList, bookID)
+		ordered = append(ordered, bookID)
+	}
+	if len(onList) > 0 {
+		return nil, utils.Validation("bookIds must list every book on the list exactly once")
+	}
+
+	updated, err := s.repo.ReorderBooks(ctx, list.ID, list.BookIDs, ordered)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, utils.Conflict("list_changed", "list was changed concurrently, retry")
+	}
+	return s.GetList(ctx, user, id)
+}
+
+func (s *ListService) getList(ctx context.Context, id string) (*models.List, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetList(ctx, bson.M{"_id": objectID})
+}
+
+// getEditableList returns the list if the user may change it. Lists the user may
+// not even see are reported as missing.
+func (s *ListService) getEditableList(ctx context.Context, user *auth.User, id string) (*models.List, error) {
+	list, err := s.getList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if canEdit(user, list) {
+		return list, nil
+	}
+	if list.Visibility == models.ListVisibilityPublic {
+		return nil, utils.Forbidden("only the owner can change this list")
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (s *ListService) insertList(ctx context.Context, list *models.List) error {
+	if list.Slug == "" {
+		return utils.Validation("name must contain letters or digits")
+	}
+	_, err := s.repo.CreateList(ctx, list)
+	if mongo.IsDuplicateKeyError(err) {
+		if list.Type == models.ListTypeCollection {
+			return utils.Conflict("list_exists", "a collection with this name or tag already exists")
+		}
+		return utils.Conflict("list_exists", "you already have a list with this name")
+	}
+	return err
+}
+
+// populateBooks loads the list's books in list order. Books deleted since they were
+// added are skipped.
+func (s *ListService) populateBooks(ctx context.Context, list *models.List) error {
+	list.Books = []models.Book{}
+	if len(list.BookIDs) == 0 {
+		return nil
+	}
+
+	books, err := s.books.GetAllBooks(ctx, bson.M{"_id": bson.M{"$in": list.BookIDs}}, nil)
+	if err != nil {
+		return err
+	}
+	byID := make(map[primitive.ObjectID]models.Book, len(books))
+	for _, book := range books {
+		byID[book.ID] = book
+	}
+	for _, bookID := range list.BookIDs {
+		if book, ok := byID[bookID]; ok {
+			list.Books = append(list.Books, book)
+		}
+	}
+	return nil
+}
+
+func newList(ownerID, listType, name, description, visibility string) *models.List {
+	now := time.Now().UTC()
+	return &models.List{
+		ID:          primitive.NewObjectID(),
+		OwnerID:     ownerID,
+		Type:        listType,
+		Name:        strings.TrimSpace(name),
+		Slug:        utils.Slugify(name),
+		Description: description,
+		Visibility:  visibility,
+		BookIDs:     []primitive.ObjectID{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// canEdit reports whether the user may change the list: owners edit their own
+// lists and any staff member edits collections.
+func canEdit(user *auth.User, list *models.List) bool {
+	if list.Type == models.ListTypeCollection {
+		return user.IsStaff()
+	}
+	return list.OwnerID == user.ID
+}
+
+func isDefaultShelf(list *models.List) bool {
+	return list.Type == models.ListTypeToRead || list.Type == models.ListTypeFavorites
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.New("failed to generate share token: " + err.Error())
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,74 @@
+package loansController
+
+import (
+	"fiber-app/src/auth"
+	"fiber-app/src/loans/dtos"
+	loanService "fiber-app/src/loans/services"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LoanController struct {
+	loanService *loanService.LoanService
+}
+
+func NewLoanController() *LoanController {
+	return &LoanController{
+		loanService: loanService.NewLoanService(),
+	}
+}
+
+func (lc *LoanController) GetLoans(c *fiber.Ctx) error {
+	filter := new(dtos.LoanFilter)
+	if err := utils.ParseQuery(c, filter); err != nil {
+		return err
+	}
+
+	loans, err := lc.loanService.GetLoans(c.UserContext(), auth.CurrentUser(c), filter)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": loans})
+}
+
+func (lc *LoanController) GetLoan(c *fiber.Ctx) error {
+	loan, err := lc.loanService.GetLoan(c.UserContext(), auth.CurrentUser(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": loan})
+}
+
+// Checkout lends the copy given by copyId or barcode to the caller, or to userId
+// when a staff member checks out on a borrower's behalf.
+func (lc *LoanController) Checkout(c *fiber.Ctx) error {
+	dto := new(dtos.CheckoutDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	loan, err := lc.loanService.Checkout(c.UserContext(), auth.CurrentUser(c), dto)
+	if err != nil {
+		return err
+	}
+
+	c.Location("/loans/" + loan.ID.Hex())
+	return c.Status(201).JSON(fiber.Map{"result": loan})
+}
+
+func (lc *LoanController) ReturnLoan(c *fiber.Ctx) error {
+	loan, err := lc.loanService.Return(c.UserContext(), auth.CurrentUser(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": loan})
+}
+
+func (lc *LoanController) RenewLoan(c *fiber.Ctx) error {
+	loan, err := lc.loanService.Renew(c.UserContext(), auth.CurrentUser(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": loan})
+}
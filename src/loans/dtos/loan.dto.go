@@ -0,0 +1,24 @@
+package dtos
+
+import "fiber-app/src/utils"
+
+// CheckoutDTO is the body of POST /loans. The copy is picked by ID or by the
+// barcode scanned at the desk. Staff can check out for another user by setting
+// UserID; the borrower's loan policy then follows the role stored on their patron.
+type CheckoutDTO struct {
+	CopyID  string `json:"copyId,omitempty" validate:"required_without=Barcode,omitempty,objectid"`
+	Barcode string `json:"barcode,omitempty" validate:"required_without=CopyID,omitempty,max=64"`
+	UserID  string `json:"userId,omitempty" validate:"omitempty,max=128"`
+}
+
+// LoanFilter holds the query parameters of the loan list endpoint. Members only
+// ever see their own loans, so UserID is for staff.
+type LoanFilter struct {
+	UserID string `query:"userId"`
+	BookID string `query:"bookId"`
+	Status string `query:"status"`
+}
+
+func (dto *CheckoutDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LoanRepository interface {
+	GetLoans(ctx context.Context, filter interface{}) ([]models.Loan, error)
+	GetLoanByID(ctx context.Context, id string) (*models.Loan, error)
+	CreateLoan(ctx context.Context, loan *models.Loan) (*mongo.InsertOneResult, error)
+	CloseLoan(ctx context.Context, id primitive.ObjectID, returnedAt time.Time) (*models.Loan, error)
+	RenewLoan(ctx context.Context, id primitive.ObjectID, renewals int, dueAt time.Time) (*models.Loan, error)
+	AdjustActiveLoans(ctx context.Context, userID string, role string, delta int) (*models.Patron, error)
+	WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error)
+}
+
+type loanRepository struct {
+	commonRepo  *common.CommonRepository
+	patronsRepo *common.CommonRepository
+}
+
+// NewLoanRepository takes the patrons collection as well, which holds the per-user
+// active loan counter that enforces loan limits.
+func NewLoanRepository(collection *mongo.Collection, patrons *mongo.Collection) LoanRepository {
+	return &loanRepository{
+		commonRepo:  common.NewCommonRepository(collection),
+		patronsRepo: common.NewCommonRepository(patrons),
+	}
+}
+
+func (r *loanRepository) GetLoans(ctx context.Context, filter interface{}) ([]models.Loan, error) {
+	var loans []models.Loan
+	err := r.commonRepo.FindAll(ctx, filter, &loans, options.Find().SetSort(bson.M{"checkedOutAt": -1}))
+	return loans, err
+}
+
+func (r *loanRepository) GetLoanByID(ctx context.Context, id string) (*models.Loan, error) {
+	objectID, err := r.commonRepo.ConvertID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var loan models.Loan
+	err = r.commonRepo.FindOne(ctx, bson.M{"_id": objectID}, &loan)
+	return &loan, err
+}
+
+func (r *loanRepository) CreateLoan(ctx context.Context, loan *models.Loan) (*mongo.InsertOneResult, error) {
+	return r.commonRepo.InsertOne(ctx, loan)
+}
+
+// CloseLoan marks an active loan as returned and returns it. It returns
+// mongo.ErrNoDocuments when the loan is not active.
+func (r *loanRepository) CloseLoan(ctx context.Context, id primitive.ObjectID, returnedAt time.Time) (*models.Loan, error) {
+	update := bson.M{"$set": bson.M{"status": models.LoanStatusReturned, "returnedAt": returnedAt}}
+	return r.findAndModify(ctx, bson.M{"_id": id, "status": models.LoanStatusActive}, update)
+}
+
+// RenewLoan moves the due date of an active loan that has been renewed exactly
+// renewals times, so two concurrent renewals cannot both succeed. It returns
+// mongo.ErrNoDocuments when the loan changed in between.
+func (r *loanRepository) RenewLoan(ctx context.Context, id primitive.ObjectID, renewals int, dueAt time.Time) (*models.Loan, error) {
+	filter := bson.M{"_id": id, "status": models.LoanStatusActive, "renewals": renewals}
+	update := bson.M{"$set": bson.M{"dueAt": dueAt}, "$inc": bson.M{"renewals": 1}}
+	return r.findAndModify(ctx, filter, update)
+}
+
+// AdjustActiveLoans adds delta to the user's active loan count, creating the patron
+// on first use, and returns the patron after the change. A non-empty role is stored
+// on the patron as well.
+func (r *loanRepository) AdjustActiveLoans(ctx context.Context, userID string, role string, delta int) (*models.Patron, error) {
+	now := time.Now().UTC()
+	set := bson.M{"updatedAt": now}
+	if role != "" {
+		set["role"] = role
+	}
+	update := bson.M{
+		"$inc":         bson.M{"activeLoans": delta},
+		"$set":         set,
+		"$setOnInsert": bson.M{"createdAt": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	res, err := r.patronsRepo.FindAndModify(ctx, bson.M{"_id": userID}, update, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var patron models.Patron
+	err = res.Decode(&patron)
+	return &patron, err
+}
+
+func (r *loanRepository) WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error) {
+	return r.commonRepo.WithTransaction(ctx, fn)
+}
+
+func (r *loanRepository) findAndModify(ctx context.Context, filter interface{}, update interface{}) (*models.Loan, error) {
+	res, err := r.commonRepo.FindAndModify(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After))
+	if err != nil {
+		return nil, err
+	}
+
+	var loan models.Loan
+	err = res.Decode(&loan)
+	return &loan, err
+}
@@ -0,0 +1,28 @@
+package loanService
+
+import (
+	"time"
+
+	"fiber-app/src/auth"
+)
+
+// LoanPolicy is what a borrower of a given role is allowed.
+type LoanPolicy struct {
+	MaxLoans    int           // Copies the borrower may have checked out at once.
+	LoanPeriod  time.Duration // Time from checkout or renewal to the due date.
+	MaxRenewals int           // Renewals allowed per loan.
+}
+
+var loanPolicies = map[string]LoanPolicy{
+	auth.RoleMember: {MaxLoans: 5, LoanPeriod: 14 * 24 * time.Hour, MaxRenewals: 2},
+	auth.RoleStaff:  {MaxLoans: 10, LoanPeriod: 28 * 24 * time.Hour, MaxRenewals: 3},
+	auth.RoleAdmin:  {MaxLoans: 20, LoanPeriod: 28 * 24 * time.Hour, MaxRenewals: 3},
+}
+
+// PolicyFor returns the loan policy of role, falling back to the member policy.
+func PolicyFor(role string) LoanPolicy {
+	if policy, ok := loanPolicies[role]; ok {
+		return policy
+	}
+	return loanPolicies[auth.RoleMember]
+}
@@ -0,0 +1,246 @@
+package loanService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fiber-app/src/auth"
+	"fiber-app/src/common"
+	copyService "fiber-app/src/copies/services"
+	fineService "fiber-app/src/fines/services"
+	holdService "fiber-app/src/holds/services"
+	"fiber-app/src/loans/dtos"
+	"fiber-app/src/loans/repository"
+	"fiber-app/src/models"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type LoanService struct {
+	repo   repository.LoanRepository
+	copies *copyService.CopyService
+	holds  *holdService.HoldService
+	fines  *fineService.FineService
+}
+
+// NewLoanService initializes the repository and returns a new LoanService instance.
+func NewLoanService() *LoanService {
+	repo := repository.NewLoanRepository(common.GetDBCollection("loans"), common.GetDBCollection("patrons"))
+	return &LoanService{
+		repo:   repo,
+		copies: copyService.NewCopyService(),
+		holds:  holdService.NewHoldService(),
+		fines:  fineService.NewFineService(),
+	}
+}
+
+// GetLoans lists loans matching filter. Members only see their own loans.
+func (s *LoanService) GetLoans(ctx context.Context, user *auth.User, filter *dtos.LoanFilter) ([]models.Loan, error) {
+	query := bson.M{}
+	if filter.UserID != "" {
+		query["userId"] = filter.UserID
+	}
+	if !user.IsStaff() {
+		query["userId"] = user.ID
+	}
+	if filter.BookID != "" {
+		bookID, err := primitive.ObjectIDFromHex(filter.BookID)
+		if err != nil {
+			return nil, utils.Validation("invalid bookId")
+		}
+		query["bookId"] = bookID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	return s.repo.GetLoans(ctx, query)
+}
+
+// GetLoan returns a loan the user is allowed to see.
+func (s *LoanService) GetLoan(ctx context.Context, user *auth.User, id string) (*models.Loan, error) {
+	loan, err := s.repo.GetLoanByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLoanAccess(user, loan); err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+// Checkout lends a copy to the user, or to dto.UserID when staff check out on a
+// borrower's behalf. The patron's loan counter, the copy's status, the book's
+// available count, any hold on the copy and the loan itself change in one
+// transaction, so a copy can never be lent twice and a borrower can never go over
+// their limit. A copy on hold can only be checked out by the reader it is held for,
+// and borrowers with too much in outstanding fines cannot check out at all.
+func (s *LoanService) Checkout(ctx context.Context, user *auth.User, dto *dtos.CheckoutDTO) (*models.Loan, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+
+	// The role of a borrower served by staff is not asserted by the request, so it is
+	// read from their patron, where their own checkouts record it.
+	borrower, role, checkedOutBy := user.ID, user.Role, ""
+	if dto.UserID != "" && dto.UserID != user.ID {
+		if !user.IsStaff() {
+			return nil, utils.Forbidden("only staff can check out for another user")
+		}
+		borrower, role, checkedOutBy = dto.UserID, "", user.ID
+	}
+
+	copyFilter := bson.M{"barcode": strings.TrimSpace(dto.Barcode)}
+	if dto.CopyID != "" {
+		copyID, _ := primitive.ObjectIDFromHex(dto.CopyID)
+		copyFilter = bson.M{"_id": copyID}
+	}
+
+	loan, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		patron, err := s.repo.AdjustActiveLoans(sessCtx, borrower, role, 1)
+		if err != nil {
+			return nil, err
+		}
+		role := patron.Role
+		if role == "" {
+			role = auth.RoleMember
+		}
+		policy := PolicyFor(role)
+		if patron.ActiveLoans > policy.MaxLoans {
+			return nil, utils.Conflict("loan_limit_reached", fmt.Sprintf("loan limit of %d reached", policy.MaxLoans))
+		}
+		if err := s.fines.CheckCanBorrow(patron); err != nil {
+			return nil, err
+		}
+
+		copy, err := s.copies.GetCopy(sessCtx, copyFilter)
+		if err != nil {
+			return nil, err
+		}
+		if copy.Status == models.CopyStatusOnHold {
+			if _, err := s.holds.FulfillHold(sessCtx, copy.ID, borrower); err != nil {
+				return nil, err
+			}
+			copy, err = s.copies.MoveCopy(sessCtx, bson.M{"_id": copy.ID}, models.CopyStatusOnHold, models.CopyStatusOnLoan)
+		} else {
+			copy, err = s.copies.CheckOut(sessCtx, bson.M{"_id": copy.ID})
+			if err == nil {
+				err = s.holds.FulfillWaiting(sessCtx, copy.BookID, borrower)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now().UTC()
+		loan := &models.Loan{
+			ID:           primitive.NewObjectID(),
+			CopyID:       copy.ID,
+			BookID:       copy.BookID,
+			UserID:       borrower,
+			Role:         role,
+			Status:       models.LoanStatusActive,
+			CheckedOutAt: now,
+			DueAt:        now.Add(policy.LoanPeriod),
+			CheckedOutBy: checkedOutBy,
+		}
+		if _, err := s.repo.CreateLoan(sessCtx, loan); err != nil {
+			return nil, err
+		}
+		return loan, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loan.(*models.Loan), nil
+}
+
+// Return checks a loaned copy back in and frees a slot under the borrower's limit.
+// The copy goes to the first reader waiting for the book, if any, and otherwise
+// back on the shelf. A late return is fined up to the return time.
+func (s *LoanService) Return(ctx context.Context, user *auth.User, id string) (*models.Loan, error) {
+	current, err := s.GetLoan(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	loan, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		returnedAt := time.Now().UTC()
+		loan, err := s.repo.CloseLoan(sessCtx, current.ID, returnedAt)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, utils.Conflict("loan_not_active", "loan has already been returned")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.fines.Accrue(sessCtx, loan, returnedAt); err != nil {
+			return nil, err
+		}
+		if _, err := s.holds.ReleaseCopy(sessCtx, loan.CopyID, loan.BookID, models.CopyStatusOnLoan); err != nil {
+			return nil, err
+		}
+		if _, err := s.repo.AdjustActiveLoans(sessCtx, loan.UserID, "", -1); err != nil {
+			return nil, err
+		}
+		return loan, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loan.(*models.Loan), nil
+}
+
+// Renew extends the due date of a loan by another loan period from now. Overdue
+// loans have to be returned instead, and loans of a book others are waiting for
+// cannot be renewed.
+func (s *LoanService) Renew(ctx context.Context, user *auth.User, id string) (*models.Loan, error) {
+	loan, err := s.GetLoan(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	if loan.Status != models.LoanStatusActive {
+		return nil, utils.Conflict("loan_not_active", "loan has already been returned")
+	}
+
+	now := time.Now().UTC()
+	policy := PolicyFor(loan.Role)
+	if loan.Renewals >= policy.MaxRenewals {
+		return nil, utils.Conflict("renewal_limit_reached", fmt.Sprintf("loan has been renewed %d times already", loan.Renewals))
+	}
+	if now.After(loan.DueAt) {
+		return nil, utils.Conflict("loan_overdue", "overdue loans cannot be renewed")
+	}
+
+	waiting, err := s.holds.HasWaitingHolds(ctx, loan.BookID)
+	if err != nil {
+		return nil, err
+	}
+	if waiting {
+		return nil, utils.Conflict("book_on_hold", "other readers are waiting for this book")
+	}
+
+	dueAt := now.Add(policy.LoanPeriod)
+	if dueAt.Before(loan.DueAt) {
+		dueAt = loan.DueAt
+	}
+
+	renewed, err := s.repo.RenewLoan(ctx, loan.ID, loan.Renewals, dueAt)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, utils.Conflict("loan_changed", "loan was changed concurrently, retry")
+	}
+	return renewed, err
+}
+
+// checkLoanAccess lets members act only on their own loans.
+func checkLoanAccess(user *auth.User, loan *models.Loan) error {
+	if user.IsStaff() || loan.UserID == user.ID {
+		return nil
+	}
+	return utils.Forbidden("loan belongs to another user")
+}
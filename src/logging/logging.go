@@ -0,0 +1,172 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"fiber-app/src/config"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys every record may carry besides the message.
+const (
+	ModuleKey    = "module"
+	RequestIDKey = "request_id"
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+)
+
+var (
+	// base writes JSON to stdout, redacting sensitive attributes. Module loggers
+	// share it and differ only in their level.
+	base = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		// Levels are filtered per module, so the base handler lets everything through.
+		Level:       slog.Level(-8),
+		ReplaceAttr: redactAttr,
+	})
+
+	mu sync.Mutex
+	// defaultLevel applies to modules LOG_LEVELS does not name.
+	defaultLevel slog.Level
+	levels       = map[string]*slog.LevelVar{}
+	overrides    = map[string]slog.Level{}
+)
+
+// For returns the logger of module, such as "jobs" or "http". Its records carry the
+// module name and pass only at or above the module's level. Loggers can be created
+// before Init, as package variables; Init adjusts their levels afterwards.
+func For(module string) *slog.Logger {
+	return slog.New(&contextHandler{
+		Handler: base.WithAttrs([]slog.Attr{slog.String(ModuleKey, module)}),
+		level:   levelOf(module),
+	})
+}
+
+func levelOf(module string) *slog.LevelVar {
+	mu.Lock()
+	defer mu.Unlock()
+	if level, ok := levels[module]; ok {
+		return level
+	}
+	level := new(slog.LevelVar)
+	if override, ok := overrides[module]; ok {
+		level.Set(override)
+	} else {
+		level.Set(defaultLevel)
+	}
+	levels[module] = level
+	return level
+}
+
+// Init sets the log levels from cfg, the default level such as "info" and the
+// per-module overrides such as "jobs=debug,scheduler=warn", and makes the "app"
+// logger slog's default.
+func Init(cfg config.LogConfig) error {
+	level := slog.LevelInfo
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return fmt.Errorf("logging: LOG_LEVEL: %w", err)
+		}
+	}
+	modules, err := parseLevels(cfg.Levels)
+	if err != nil {
+		return err
+	}
+	SetLevels(level, modules)
+	slog.SetDefault(For("app"))
+	return nil
+}
+
+// SetLevels sets the default level and the per-module overrides, replacing any
+// set before, on existing and future module loggers alike.
+func SetLevels(level slog.Level, modules map[string]slog.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultLevel = level
+	overrides = modules
+	for module, levelVar := range levels {
+		if override, ok := modules[module]; ok {
+			levelVar.Set(override)
+		} else {
+			levelVar.Set(level)
+		}
+	}
+}
+
+func parseLevels(value string) (map[string]slog.Level, error) {
+	modules := map[string]slog.Level{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		module, name, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(module) == "" {
+			return nil, fmt.Errorf("logging: LOG_LEVELS: %q is not module=level", entry)
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+			return nil, fmt.Errorf("logging: LOG_LEVELS: %s: %w", module, err)
+		}
+		modules[strings.TrimSpace(module)] = level
+	}
+	return modules, nil
+}
+
+// Err is the attribute records use for an error.
+func Err(err error) slog.Attr {
+	return slog.Any("error", err)
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID id, which records
+// logged with the context then include.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID in ctx, or "" when there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler filters records by its module's level and adds the request ID
+// and the trace and span IDs found in the context they are logged with.
+type contextHandler struct {
+	slog.Handler
+	level slog.Leveler
+}
+
+func (h *contextHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			record.AddAttrs(slog.String(RequestIDKey, id))
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			record.AddAttrs(
+				slog.String(TraceIDKey, span.TraceID().String()),
+				slog.String(SpanIDKey, span.SpanID().String()),
+			)
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+	fiberutils "github.com/gofiber/fiber/v2/utils"
+)
+
+// HeaderRequestID carries the request ID in both directions.
+const HeaderRequestID = fiber.HeaderXRequestID
+
+// maxRequestIDLength bounds the request IDs accepted from clients.
+const maxRequestIDLength = 128
+
+var httpLogger = For("http")
+
+// Middleware tags every request with an ID, taken from the X-Request-ID header or
+// generated, echoes it back in the response and puts it on c.UserContext() so
+// records logged with that context carry it. Once the request is handled it logs
+// one access record. Errors are rendered here with the app's ErrorHandler so the
+// record has the final status.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(HeaderRequestID)
+		if !validRequestID(id) {
+			id = fiberutils.UUIDv4()
+		}
+		c.Set(HeaderRequestID, id)
+		c.SetUserContext(WithRequestID(c.UserContext(), id))
+
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		httpLogger.LogAttrs(c.UserContext(), level, "request",
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.String("route", utils.RouteTemplate(c)),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", c.IP()),
+		)
+		return nil
+	}
+}
+
+// validRequestID accepts client IDs of printable ASCII up to maxRequestIDLength, so
+// they cannot forge log lines or bloat them.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Redacted replaces the value of sensitive attributes.
+const Redacted = "[REDACTED]"
+
+// sensitive lists substrings of keys, lowercased and without separators, whose
+// values are never logged.
+var sensitive = []string{"password", "passwd", "secret", "token", "apikey", "authorization", "cookie", "credential"}
+
+// IsSensitive reports whether values under key must be redacted.
+func IsSensitive(key string) bool {
+	key = strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+	for _, word := range sensitive {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAttr is the base handler's ReplaceAttr. Besides attributes with sensitive
+// keys, it redacts sensitive entries of maps and BSON documents logged as values,
+// such as query filters.
+func redactAttr(_ []string, attr slog.Attr) slog.Attr {
+	if IsSensitive(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	if attr.Value.Kind() == slog.KindAny {
+		if value, ok := redactValue(attr.Value.Any()); ok {
+			return slog.Any(attr.Key, value)
+		}
+	}
+	return attr
+}
+
+// redactValue returns a copy of v with sensitive entries redacted, and whether v
+// is a kind of value it looks into.
+func redactValue(v interface{}) (interface{}, bool) {
+	switch doc := v.(type) {
+	case primitive.D:
+		// Logged as an object rather than a list of key and value pairs.
+		out := make(map[string]interface{}, len(doc))
+		for _, elem := range doc {
+			out[elem.Key] = redactEntry(elem.Key, elem.Value)
+		}
+		return out, true
+	case primitive.A:
+		out := make(primitive.A, len(doc))
+		for i, elem := range doc {
+			out[i] = redactElem(elem)
+		}
+		return out, true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v, false
+		}
+		out := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			out[key] = redactEntry(key, iter.Value().Interface())
+		}
+		return out, true
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return v, false
+		}
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = redactElem(rv.Index(i).Interface())
+		}
+		return out, true
+	}
+	return v, false
+}
+
+func redactEntry(key string, value interface{}) interface{} {
+	if IsSensitive(key) {
+		return Redacted
+	}
+	return redactElem(value)
+}
+
+func redactElem(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	redacted, _ := redactValue(value)
+	return redacted
+}
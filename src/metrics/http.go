@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	httpRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by method, route template and status.",
+	}, []string{"method", "route", "status"})
+	httpDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time to handle HTTP requests, by method, route template and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	httpInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests being handled.",
+	})
+)
+
+// Middleware records every request under its route template, such as /books/:id,
+// so IDs do not blow up the number of series. Requests no route matched are recorded
+// as "unmatched". Errors are rendered here with the app's ErrorHandler so the status
+// they end up with is the one recorded.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := utils.RouteTemplate(c)
+		status := strconv.Itoa(c.Response().StatusCode())
+		httpRequests.WithLabelValues(c.Method(), route, status).Inc()
+		httpDuration.WithLabelValues(c.Method(), route, status).Observe(time.Since(start).Seconds())
+		return nil
+	}
+}
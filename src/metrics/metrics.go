@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every metric the app exports, along with the Go runtime and
+// process collectors.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+// Business counters. Modules add to them where the event happens.
+var (
+	BooksCreated = factory.NewCounter(prometheus.CounterOpts{
+		Name: "books_created_total",
+		Help: "Books added to the catalog, one at a time or through batches and imports.",
+	})
+	BooksDeleted = factory.NewCounter(prometheus.CounterOpts{
+		Name: "books_deleted_total",
+		Help: "Books deleted from the catalog.",
+	})
+	// AuthenticatedRequests counts requests that carry a user ID from the gateway,
+	// by result: accepted, or rejected for an unknown role.
+	AuthenticatedRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "authenticated_requests_total",
+		Help: "Requests carrying a gateway user ID, by whether their identity was accepted or rejected.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var (
+	mongoCommands = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongo_commands_total",
+		Help: "MongoDB commands run, by command name and result: succeeded or failed.",
+	}, []string{"command", "result"})
+	mongoDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongo_command_duration_seconds",
+		Help:    "Round trip time of MongoDB commands, by command name.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"command"})
+	mongoConnections = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "mongo_pool_connections",
+		Help: "Open connections in the MongoDB pools.",
+	})
+	mongoConnectionsInUse = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "mongo_pool_connections_in_use",
+		Help: "MongoDB connections checked out of the pools.",
+	})
+	mongoCheckoutFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongo_pool_checkout_failures_total",
+		Help: "Failed MongoDB connection checkouts, by reason.",
+	}, []string{"reason"})
+)
+
+// CommandMonitor returns a driver monitor that records every command's result and
+// duration.
+func CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			mongoCommands.WithLabelValues(e.CommandName, "succeeded").Inc()
+			mongoDuration.WithLabelValues(e.CommandName).Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			mongoCommands.WithLabelValues(e.CommandName, "failed").Inc()
+			mongoDuration.WithLabelValues(e.CommandName).Observe(e.Duration.Seconds())
+		},
+	}
+}
+
+// PoolMonitor returns a driver monitor that tracks open and checked out connections.
+func PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				mongoConnections.Inc()
+			case event.ConnectionClosed:
+				mongoConnections.Dec()
+			case event.GetSucceeded:
+				mongoConnectionsInUse.Inc()
+			case event.ConnectionReturned:
+				mongoConnectionsInUse.Dec()
+			case event.GetFailed:
+				mongoCheckoutFailures.WithLabelValues(e.Reason).Inc()
+			}
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"fiber-app/src/common"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// booksAuthorRefs moves the free-text author of each book into the authors collection
+// and replaces it with a reference. Names are matched on their normalized form, so
+// "J.K. Rowling" and "jk rowling" end up as one author. The unique index on
+// normalizedName is created first so concurrent find-or-create calls cannot race.
+var booksAuthorRefs = Migration{
+	ID:          "20261019-04-books-author-refs",
+	Description: "move book authors into the authors collection",
+	Up: func(ctx context.Context) error {
+		authors := common.GetDBCollection("authors")
+		books := common.GetDBCollection("books")
+
+		_, err := authors.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "normalizedName", Value: 1}},
+			Options: options.Index().SetName("normalizedName_unique").SetUnique(true),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = books.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "authorIds", Value: 1}}})
+		if err != nil {
+			return err
+		}
+
+		cursor, err := books.Find(ctx, bson.M{"author": bson.M{"$exists": true}}, options.Find().SetProjection(bson.M{"author": 1}))
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		ids := make(map[string]primitive.ObjectID)
+		for cursor.Next(ctx) {
+			var doc struct {
+				ID     primitive.ObjectID `bson:"_id"`
+				Author interface{}        `bson:"author"`
+			}
+			if err := cursor.Decode(&doc); err != nil {
+				return err
+			}
+
+			set := bson.M{}
+			if name, ok := doc.Author.(string); ok && strings.TrimSpace(name) != "" {
+				name = strings.TrimSpace(name)
+				key := utils.NormalizePersonName(name)
+				id, ok := ids[key]
+				if !ok {
+					id, err = findOrCreateAuthor(ctx, authors, name, key)
+					if err != nil {
+						return err
+					}
+					ids[key] = id
+				}
+				set["authorIds"] = bson.A{id}
+			}
+
+			update := bson.M{"$unset": bson.M{"author": ""}}
+			if len(set) > 0 {
+				update["$set"] = set
+			}
+			if _, err := books.UpdateOne(ctx, bson.M{"_id": doc.ID}, update); err != nil {
+				return err
+			}
+		}
+		return cursor.Err()
+	},
+}
+
+func findOrCreateAuthor(ctx context.Context, authors *mongo.Collection, name, normalizedName string) (primitive.ObjectID, error) {
+	now := time.Now().UTC()
+	update := bson.M{"$setOnInsert": bson.M{
+		"name":           name,
+		"normalizedName": normalizedName,
+		"createdAt":      now,
+		"updatedAt":      now,
+	}}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"_id": 1})
+
+	var author struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	err := authors.FindOneAndUpdate(ctx, bson.M{"normalizedName": normalizedName}, update, opts).Decode(&author)
+	return author.ID, err
+}
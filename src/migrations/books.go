@@ -0,0 +1,161 @@
+package migrations
+
+import (
+	"context"
+
+	"fiber-app/src/common"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// booksIntYear converts the old four-character string years to integers and backfills
+// timestamps from the ObjectID creation time. Years that are not numbers are dropped.
+var booksIntYear = Migration{
+	ID:          "20261019-01-books-int-year",
+	Description: "convert book years to integers and backfill timestamps",
+	Up: func(ctx context.Context) error {
+		books := common.GetDBCollection("books")
+
+		_, err := books.UpdateMany(ctx, bson.M{"year": bson.M{"$type": "string"}}, mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"year": bson.M{"$convert": bson.M{
+				"input":   bson.M{"$trim": bson.M{"input": "$year"}},
+				"to":      "int",
+				"onError": nil,
+				"onNull":  nil,
+			}}}}},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = books.UpdateMany(ctx, bson.M{"year": bson.M{"$type": "null"}}, bson.M{"$unset": bson.M{"year": ""}})
+		if err != nil {
+			return err
+		}
+
+		_, err = books.UpdateMany(ctx, bson.M{"createdAt": bson.M{"$exists": false}}, mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"createdAt": bson.M{"$toDate": "$_id"},
+				"updatedAt": bson.M{"$ifNull": bson.A{"$updatedAt", bson.M{"$toDate": "$_id"}}},
+			}}},
+		})
+		return err
+	},
+}
+
+// booksNormalizeISBN rewrites stored ISBNs to the canonical ISBN-13 form. Values that
+// are not valid ISBNs are moved to legacyIsbn so they cannot block the unique index.
+var booksNormalizeISBN = Migration{
+	ID:          "20261019-02-books-normalize-isbn",
+	Description: "normalize book ISBNs to ISBN-13",
+	Up: func(ctx context.Context) error {
+		books := common.GetDBCollection("books")
+
+		cursor, err := books.Find(ctx, bson.M{"isbn": bson.M{"$type": "string"}}, options.Find().SetProjection(bson.M{"isbn": 1}))
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		for cursor.Next(ctx) {
+			var doc struct {
+				ID   interface{} `bson:"_id"`
+				ISBN string      `bson:"isbn"`
+			}
+			if err := cursor.Decode(&doc); err != nil {
+				return err
+			}
+
+			var update bson.M
+			if isbn, ok := utils.NormalizeISBN(doc.ISBN); !ok {
+				update = bson.M{"$set": bson.M{"legacyIsbn": doc.ISBN}, "$unset": bson.M{"isbn": ""}}
+			} else if isbn != doc.ISBN {
+				update = bson.M{"$set": bson.M{"isbn": isbn}}
+			} else {
+				continue
+			}
+			if _, err := books.UpdateOne(ctx, bson.M{"_id": doc.ID}, update); err != nil {
+				return err
+			}
+		}
+		return cursor.Err()
+	},
+}
+
+// booksIndexes adds the unique ISBN index and the indexes used by the list filters.
+// When several books already share an ISBN, the oldest keeps it and the others have
+// it moved to legacyIsbn, like invalid ones, so the index can be built; the books
+// are logged so they can be merged.
+var booksIndexes = Migration{
+	ID:          "20261019-03-books-indexes",
+	Description: "create book indexes",
+	Up: func(ctx context.Context) error {
+		books := common.GetDBCollection("books")
+
+		cursor, err := books.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"isbn": bson.M{"$type": "string"}}}},
+			{{Key: "$sort", Value: bson.M{"_id": 1}}},
+			{{Key: "$group", Value: bson.M{"_id": "$isbn", "ids": bson.M{"$push": "$_id"}}}},
+			{{Key: "$match", Value: bson.M{"ids.1": bson.M{"$exists": true}}}},
+		})
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		for cursor.Next(ctx) {
+			var group struct {
+				ISBN string        `bson:"_id"`
+				IDs  []interface{} `bson:"ids"`
+			}
+			if err := cursor.Decode(&group); err != nil {
+				return err
+			}
+			_, err := books.UpdateMany(ctx,
+				bson.M{"_id": bson.M{"$in": group.IDs[1:]}},
+				bson.M{"$set": bson.M{"legacyIsbn": group.ISBN}, "$unset": bson.M{"isbn": ""}},
+			)
+			if err != nil {
+				return err
+			}
+			logger.WarnContext(ctx, "moved duplicate isbn to legacyIsbn", "isbn", group.ISBN, "kept", group.IDs[0], "moved", group.IDs[1:])
+		}
+		if err := cursor.Err(); err != nil {
+			return err
+		}
+
+		_, err = books.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{
+				Keys: bson.D{{Key: "isbn", Value: 1}},
+				Options: options.Index().
+					SetName("isbn_unique").
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"isbn": bson.M{"$type": "string"}}),
+			},
+			{Keys: bson.D{{Key: "year", Value: 1}}},
+			{Keys: bson.D{{Key: "genres", Value: 1}}},
+			{Keys: bson.D{{Key: "language", Value: 1}}},
+		})
+		return err
+	},
+}
+
+// booksImportKey indexes the row keys import jobs insert under, so a retried job
+// finds the rows it already inserted without scanning the catalog.
+var booksImportKey = Migration{
+	ID:          "20261019-12-books-import-key",
+	Description: "index book import row keys",
+	Up: func(ctx context.Context) error {
+		_, err := common.GetDBCollection("books").Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "importKey", Value: 1}},
+			Options: options.Index().
+				SetName("importKey_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"importKey": bson.M{"$type": "string"}}),
+		})
+		return err
+	},
+}
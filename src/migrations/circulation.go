@@ -0,0 +1,149 @@
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// circulationIndexes creates the copy and loan indexes. The partial unique index on
+// active loans is the last line of defence against lending one copy twice.
+var circulationIndexes = Migration{
+	ID:          "20261019-05-circulation-indexes",
+	Description: "create copy and loan indexes",
+	Up: func(ctx context.Context) error {
+		_, err := common.GetDBCollection("copies").Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "barcode", Value: 1}},
+				Options: options.Index().SetName("barcode_unique").SetUnique(true),
+			},
+			{Keys: bson.D{{Key: "bookId", Value: 1}, {Key: "status", Value: 1}}},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = common.GetDBCollection("loans").Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{
+				Keys: bson.D{{Key: "copyId", Value: 1}},
+				Options: options.Index().
+					SetName("copyId_active_unique").
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"status": models.LoanStatusActive}),
+			},
+			{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "status", Value: 1}}},
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "dueAt", Value: 1}}},
+		})
+		return err
+	},
+}
+
+// holdIndexes creates the indexes that serve the hold queues and the expiry task.
+var holdIndexes = Migration{
+	ID:          "20261019-06-hold-indexes",
+	Description: "create hold indexes",
+	Up: func(ctx context.Context) error {
+		_, err := common.GetDBCollection("holds").Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "bookId", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}},
+			{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "status", Value: 1}}},
+			{Keys: bson.D{{Key: "copyId", Value: 1}, {Key: "status", Value: 1}}},
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "pickupBy", Value: 1}}},
+		})
+		return err
+	},
+}
+
+// fineIndexes creates the index behind a user's fine ledger.
+var fineIndexes = Migration{
+	ID:          "20261019-07-fine-indexes",
+	Description: "create fine ledger indexes",
+	Up: func(ctx context.Context) error {
+		_, err := common.GetDBCollection("fines").Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+		})
+		return err
+	},
+}
+
+// holdOpenUnique makes a reader's open hold on a book unique, so two concurrent
+// requests cannot both queue them. Open holds duplicated before the index existed
+// are cancelled first, keeping the ready one or else the oldest.
+var holdOpenUnique = Migration{
+	ID:          "20261019-11-hold-open-unique",
+	Description: "make open holds unique per book and reader",
+	Up: func(ctx context.Context) error {
+		holds := common.GetDBCollection("holds")
+		openStatuses := bson.A{models.HoldStatusWaiting, models.HoldStatusReady}
+
+		cursor, err := holds.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"status": bson.M{"$in": openStatuses}}}},
+			{{Key: "$sort", Value: bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}}},
+			{{Key: "$group", Value: bson.M{
+				"_id":   bson.M{"bookId": "$bookId", "userId": "$userId"},
+				"holds": bson.M{"$push": bson.M{"_id": "$_id", "status": "$status"}},
+				"count": bson.M{"$sum": 1},
+			}}},
+			{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		})
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		now := time.Now().UTC()
+		for cursor.Next(ctx) {
+			var group struct {
+				Holds []struct {
+					ID     primitive.ObjectID `bson:"_id"`
+					Status string             `bson:"status"`
+				} `bson:"holds"`
+			}
+			if err := cursor.Decode(&group); err != nil {
+				return err
+			}
+
+			keep := group.Holds[0].ID
+			for _, hold := range group.Holds {
+				if hold.Status == models.HoldStatusReady {
+					keep = hold.ID
+					break
+				}
+			}
+			var cancel bson.A
+			for _, hold := range group.Holds {
+				if hold.ID != keep && hold.Status == models.HoldStatusWaiting {
+					cancel = append(cancel, hold.ID)
+				}
+			}
+			if len(cancel) == 0 {
+				continue
+			}
+			_, err := holds.UpdateMany(ctx,
+				bson.M{"_id": bson.M{"$in": cancel}, "status": models.HoldStatusWaiting},
+				bson.M{"$set": bson.M{"status": models.HoldStatusCancelled, "closedAt": now, "updatedAt": now}},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			return err
+		}
+
+		_, err = holds.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "bookId", Value: 1}, {Key: "userId", Value: 1}},
+			Options: options.Index().
+				SetName("bookId_userId_open_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": bson.M{"$in": openStatuses}}),
+		})
+		return err
+	},
+}
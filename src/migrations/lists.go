@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"context"
+
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// listIndexes makes list names unique per owner, collection tags unique, share
+// tokens unique and indexes the tag filter of the book list.
+var listIndexes = Migration{
+	ID:          "20261019-09-list-indexes",
+	Description: "create list and book tag indexes",
+	Up: func(ctx context.Context) error {
+		_, err := common.GetDBCollection("lists").Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "ownerId", Value: 1}, {Key: "slug", Value: 1}},
+				Options: options.Index().SetName("ownerId_slug_unique").SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "tag", Value: 1}},
+				Options: options.Index().
+					SetName("collection_tag_unique").
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"type": models.ListTypeCollection}),
+			},
+			{
+				Keys: bson.D{{Key: "shareToken", Value: 1}},
+				Options: options.Index().
+					SetName("shareToken_unique").
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"shareToken": bson.M{"$type": "string"}}),
+			},
+			{Keys: bson.D{{Key: "type", Value: 1}}},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = common.GetDBCollection("books").Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "tags", Value: 1}}})
+		return err
+	},
+}
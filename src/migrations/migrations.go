@@ -0,0 +1,194 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"fiber-app/src/common"
+	"fiber-app/src/logging"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migration is a one-off change to stored data or indexes. IDs are applied in the
+// order migrations are listed and must never be reused.
+type Migration struct {
+	ID          string
+	Description string
+	Up          func(ctx context.Context) error
+}
+
+var logger = logging.For("migrations")
+
+// all lists every migration in the order it must run. Append new ones at the end.
+var all = []Migration{
+	booksIntYear,
+	booksNormalizeISBN,
+	booksIndexes,
+	booksAuthorRefs,
+	circulationIndexes,
+	holdIndexes,
+	fineIndexes,
+	reviewIndexes,
+	listIndexes,
+	suggestFields,
+	holdOpenUnique,
+	booksImportKey,
+}
+
+const (
+	// leaseDuration is how long a claim on a migration holds without being renewed.
+	// The instance running it renews it every leaseDuration/3, so only a crashed one
+	// lets it lapse, and another instance then takes the migration over.
+	leaseDuration = time.Minute
+	// pollInterval is how often an instance waiting for another one's migration
+	// checks whether it finished.
+	pollInterval = 2 * time.Second
+)
+
+type migrationRecord struct {
+	ID          string     `bson:"_id"`
+	Description string     `bson:"description"`
+	StartedAt   time.Time  `bson:"startedAt"`
+	HeldUntil   time.Time  `bson:"heldUntil"`
+	Owner       string     `bson:"owner"` // The Run holding the lease, so a takeover can be told from a renewal.
+	FinishedAt  *time.Time `bson:"finishedAt,omitempty"`
+}
+
+// Run applies every migration that has not finished yet, in order. A migration is
+// claimed by inserting its record, or by taking over a record whose lease lapsed
+// because the instance running it died. Instances that find a migration claimed by
+// another one wait for it to finish, so none of them serves before every migration
+// has been applied. A failed migration's record is removed so the next start
+// retries it.
+func Run(ctx context.Context) error {
+	repo := common.NewCommonRepository(common.GetDBCollection("migrations"))
+	owner := primitive.NewObjectID().Hex()
+
+	for _, m := range all {
+		claimed, err := waitOrClaim(ctx, repo, m, owner)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		logger.InfoContext(ctx, "running migration", "migration", m.ID, "description", m.Description)
+		if err := runHeld(ctx, repo, m, owner); err != nil {
+			if _, delErr := repo.DeleteOne(ctx, bson.M{"_id": m.ID, "owner": owner}); delErr != nil {
+				logger.ErrorContext(ctx, "releasing migration", "migration", m.ID, logging.Err(delErr))
+			}
+			return fmt.Errorf("migration %s failed: %w", m.ID, err)
+		}
+
+		if _, err := repo.UpdateOne(ctx, bson.M{"_id": m.ID}, bson.M{"finishedAt": time.Now().UTC()}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// waitOrClaim returns true once this instance holds m, and false once another
+// instance has finished it.
+func waitOrClaim(ctx context.Context, repo *common.CommonRepository, m Migration, owner string) (bool, error) {
+	waiting := false
+	for {
+		now := time.Now().UTC()
+		record := migrationRecord{ID: m.ID, Description: m.Description, StartedAt: now, HeldUntil: now.Add(leaseDuration), Owner: owner}
+		_, err := repo.InsertOne(ctx, record)
+		if err == nil {
+			return true, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return false, err
+		}
+
+		var current migrationRecord
+		if err := repo.FindOne(ctx, bson.M{"_id": m.ID}, &current); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				// Released by a failed run in between; try to claim it again.
+				continue
+			}
+			return false, err
+		}
+		if current.FinishedAt != nil {
+			return false, nil
+		}
+
+		// Records written before leases existed have no heldUntil, so an unfinished
+		// one of those is always taken over.
+		if !current.HeldUntil.After(now) {
+			filter := bson.M{"_id": m.ID, "finishedAt": bson.M{"$exists": false}, "heldUntil": current.HeldUntil}
+			if current.HeldUntil.IsZero() {
+				filter["heldUntil"] = bson.M{"$exists": false}
+			}
+			res, err := repo.UpdateOne(ctx, filter, bson.M{"startedAt": now, "heldUntil": now.Add(leaseDuration), "owner": owner})
+			if err != nil {
+				return false, err
+			}
+			if res.MatchedCount == 1 {
+				logger.WarnContext(ctx, "taking over abandoned migration", "migration", m.ID, "started_at", current.StartedAt)
+				return true, nil
+			}
+			continue
+		}
+
+		if !waiting {
+			logger.InfoContext(ctx, "waiting for migration run by another instance", "migration", m.ID)
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return false, fmt.Errorf("waiting for migration %s: %w", m.ID, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// runHeld runs m while renewing this instance's lease on it. Should a renewal find
+// the lease taken over, which only happens when renewals failed for a whole lease,
+// the run is cancelled.
+func runHeld(ctx context.Context, repo *common.CommonRepository, m Migration, owner string) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(leaseDuration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			res, err := repo.UpdateOne(ctx,
+				bson.M{"_id": m.ID, "owner": owner, "finishedAt": bson.M{"$exists": false}},
+				bson.M{"heldUntil": time.Now().UTC().Add(leaseDuration)},
+			)
+			if err != nil {
+				logger.WarnContext(ctx, "renewing migration lease", "migration", m.ID, logging.Err(err))
+				continue
+			}
+			if res.MatchedCount == 0 {
+				cancel(errors.New("lease on migration lost"))
+				return
+			}
+		}
+	}()
+
+	if err := m.Up(ctx); err != nil {
+		if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, err) {
+			return fmt.Errorf("%w: %w", cause, err)
+		}
+		return err
+	}
+	return nil
+}
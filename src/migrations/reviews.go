@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"context"
+
+	"fiber-app/src/common"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// reviewIndexes enforces one review per user and book and indexes the review
+// orders and the rating sort of the book list.
+var reviewIndexes = Migration{
+	ID:          "20261019-08-review-indexes",
+	Description: "create review and book rating indexes",
+	Up: func(ctx context.Context) error {
+		_, err := common.GetDBCollection("reviews").Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "bookId", Value: 1}, {Key: "userId", Value: 1}},
+				Options: options.Index().SetName("bookId_userId_unique").SetUnique(true),
+			},
+			{Keys: bson.D{{Key: "bookId", Value: 1}, {Key: "createdAt", Value: -1}}},
+			{Keys: bson.D{{Key: "bookId", Value: 1}, {Key: "rating", Value: -1}, {Key: "createdAt", Value: -1}}},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = common.GetDBCollection("books").Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "ratingAverage", Value: -1}, {Key: "ratingCount", Value: -1}},
+		})
+		return err
+	},
+}
@@ -0,0 +1,76 @@
+package migrations
+
+import (
+	"context"
+
+	"fiber-app/src/common"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// suggestFields backfills the folded book titles and author names used by the
+// suggest endpoint and indexes them for prefix lookups.
+var suggestFields = Migration{
+	ID:          "20261019-10-suggest-fields",
+	Description: "backfill and index folded titles and author names",
+	Up: func(ctx context.Context) error {
+		if err := backfillFolded(ctx, common.GetDBCollection("books"), "title", "titleFolded"); err != nil {
+			return err
+		}
+		if err := backfillFolded(ctx, common.GetDBCollection("authors"), "name", "nameFolded"); err != nil {
+			return err
+		}
+
+		if _, err := common.GetDBCollection("books").Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "titleFolded", Value: 1}, {Key: "_id", Value: 1}},
+		}); err != nil {
+			return err
+		}
+		_, err := common.GetDBCollection("authors").Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "nameFolded", Value: 1}},
+		})
+		return err
+	},
+}
+
+// backfillFolded sets target to utils.FoldText(source) on documents that lack it.
+func backfillFolded(ctx context.Context, collection *mongo.Collection, source, target string) error {
+	cursor, err := collection.Find(ctx, bson.M{target: bson.M{"$exists": false}}, options.Find().SetProjection(bson.M{source: 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var models []mongo.WriteModel
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+		_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		models = models[:0]
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		value, _ := doc[source].(string)
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc["_id"]}).
+			SetUpdate(bson.M{"$set": bson.M{target: utils.FoldText(value)}}))
+		if len(models) == 500 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
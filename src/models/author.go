@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Author struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name           string             `json:"name" bson:"name"`
+	NormalizedName string             `json:"-" bson:"normalizedName"`       // Used to match spelling variants such as "J.K." and "JK".
+	NameFolded     string             `json:"-" bson:"nameFolded,omitempty"` // utils.FoldText of the name, for prefix suggestions.
+	Bio            string             `json:"bio,omitempty" bson:"bio,omitempty"`
+	BirthYear      int                `json:"birthYear,omitempty" bson:"birthYear,omitempty"`
+	DeathYear      int                `json:"deathYear,omitempty" bson:"deathYear,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
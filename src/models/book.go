@@ -1,10 +1,47 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type Book struct {
-	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Title  string             `json:"title" bson:"title"`
-	Author string             `json:"author" bson:"author"`
-	Year   string             `json:"year" bson:"year"`
-}
\ No newline at end of file
+	ID          primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Title       string               `json:"title" bson:"title"`
+	TitleFolded string               `json:"-" bson:"titleFolded,omitempty"` // utils.FoldText of the title, for prefix suggestions.
+	AuthorIDs   []primitive.ObjectID `json:"authorIds" bson:"authorIds"`
+	Authors     []Author             `json:"authors,omitempty" bson:"authors,omitempty"` // Populated by $lookup on reads; never stored.
+	ISBN        string               `json:"isbn,omitempty" bson:"isbn,omitempty"`       // Normalized ISBN-13.
+	Year        int                  `json:"year,omitempty" bson:"year,omitempty"`
+	Publisher   string               `json:"publisher,omitempty" bson:"publisher,omitempty"`
+	Language    string               `json:"language,omitempty" bson:"language,omitempty"` // BCP 47 tag, e.g. "en" or "pt-BR".
+	Pages       int                  `json:"pages,omitempty" bson:"pages,omitempty"`
+	Genres      []string             `json:"genres,omitempty" bson:"genres,omitempty"`
+	Tags        []string             `json:"tags,omitempty" bson:"tags,omitempty"` // Curated tags, set through collections.
+	Description string               `json:"description,omitempty" bson:"description,omitempty"`
+	// Copy counts are only ever changed with $inc by the copies and loans modules.
+	// omitempty keeps book inserts and upserts from resetting them.
+	CopiesTotal     int `json:"copiesTotal" bson:"copiesTotal,omitempty"`
+	CopiesAvailable int `json:"copiesAvailable" bson:"copiesAvailable,omitempty"`
+	// Rating aggregates are likewise only written by the reviews module.
+	RatingCount   int        `json:"ratingCount" bson:"ratingCount,omitempty"`
+	RatingSum     int        `json:"-" bson:"ratingSum,omitempty"`
+	RatingAverage float64    `json:"ratingAverage" bson:"ratingAverage,omitempty"`
+	Cover         *BookCover `json:"cover,omitempty" bson:"cover,omitempty"` // Set by PUT /books/:id/cover.
+	CreatedAt     time.Time  `json:"createdAt" bson:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt" bson:"updatedAt"`
+	// Books merged into another by POST /books/merge are kept, but hidden from reads.
+	MergedInto *primitive.ObjectID `json:"mergedInto,omitempty" bson:"mergedInto,omitempty"`
+	DeletedAt  *time.Time          `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+}
+
+// BookCover describes a book's uploaded cover image. The image itself and its
+// thumbnails live in the covers blob store.
+type BookCover struct {
+	ContentType string    `json:"contentType" bson:"contentType"` // Of the original; thumbnails may differ.
+	Width       int       `json:"width" bson:"width"`
+	Height      int       `json:"height" bson:"height"`
+	Sizes       []string  `json:"sizes" bson:"sizes"`
+	UpdatedAt   time.Time `json:"updatedAt" bson:"updatedAt"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Copy statuses. Only CopyStatusAvailable copies count towards a book's
+// copiesAvailable, and only loans and holds move a copy in or out of
+// CopyStatusOnLoan and CopyStatusOnHold.
+const (
+	CopyStatusAvailable   = "available"
+	CopyStatusOnLoan      = "on_loan"
+	CopyStatusOnHold      = "on_hold" // Set aside for the reader whose hold it was assigned to.
+	CopyStatusMaintenance = "maintenance"
+	CopyStatusLost        = "lost"
+	CopyStatusWithdrawn   = "withdrawn"
+)
+
+// Copy conditions, best to worst.
+const (
+	CopyConditionNew     = "new"
+	CopyConditionGood    = "good"
+	CopyConditionFair    = "fair"
+	CopyConditionPoor    = "poor"
+	CopyConditionDamaged = "damaged"
+)
+
+// Copy is one physical item of a book on the shelves.
+type Copy struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	BookID    primitive.ObjectID `json:"bookId" bson:"bookId"`
+	Barcode   string             `json:"barcode" bson:"barcode"`
+	Location  string             `json:"location,omitempty" bson:"location,omitempty"` // Branch and shelf, e.g. "Main/Fiction A-C".
+	Condition string             `json:"condition" bson:"condition"`
+	Status    string             `json:"status" bson:"status"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
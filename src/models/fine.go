@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Fine ledger entry types. Charges are positive amounts; payments and waivers are
+// negative, so a patron's balance is the sum of their entries.
+const (
+	FineEntryCharge  = "charge"
+	FineEntryPayment = "payment"
+	FineEntryWaiver  = "waiver"
+)
+
+// FineEntry is one line of a patron's fine ledger. Amounts are in cents.
+type FineEntry struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID    string              `json:"userId" bson:"userId"`
+	LoanID    *primitive.ObjectID `json:"loanId,omitempty" bson:"loanId,omitempty"`
+	Type      string              `json:"type" bson:"type"`
+	Amount    int64               `json:"amount" bson:"amount"`
+	Note      string              `json:"note,omitempty" bson:"note,omitempty"`
+	CreatedBy string              `json:"createdBy,omitempty" bson:"createdBy,omitempty"` // Admin who recorded a payment or waiver.
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+}
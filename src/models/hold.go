@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Hold statuses. A hold waits in its book's queue until a returned copy is assigned
+// to it, then is ready for pickup until PickupBy.
+const (
+	HoldStatusWaiting   = "waiting"
+	HoldStatusReady     = "ready"
+	HoldStatusFulfilled = "fulfilled"
+	HoldStatusCancelled = "cancelled"
+	HoldStatusExpired   = "expired"
+)
+
+// Hold is a reader's place in the queue for a book with no copy on the shelf.
+type Hold struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	BookID    primitive.ObjectID  `json:"bookId" bson:"bookId"`
+	UserID    string              `json:"userId" bson:"userId"`
+	Status    string              `json:"status" bson:"status"`
+	Position  int                 `json:"position,omitempty" bson:"-"` // 1-based place in the queue while waiting.
+	CopyID    *primitive.ObjectID `json:"copyId,omitempty" bson:"copyId,omitempty"`
+	ReadyAt   *time.Time          `json:"readyAt,omitempty" bson:"readyAt,omitempty"`
+	PickupBy  *time.Time          `json:"pickupBy,omitempty" bson:"pickupBy,omitempty"`
+	ClosedAt  *time.Time          `json:"closedAt,omitempty" bson:"closedAt,omitempty"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Job statuses.
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// JobError is a non-fatal, per-item error recorded while a job runs.
+type JobError struct {
+	Row     int    `json:"row,omitempty" bson:"row,omitempty"`
+	Field   string `json:"field,omitempty" bson:"field,omitempty"`
+	Message string `json:"message" bson:"message"`
+}
+
+// Job is a unit of background work. Workers lease a job before running it so that
+// only one instance processes it at a time; an expired lease makes it claimable again.
+type Job struct {
+	ID             primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Type           string              `json:"type" bson:"type"`
+	Status         string              `json:"status" bson:"status"`
+	Params         map[string]string   `json:"params,omitempty" bson:"params,omitempty"`
+	CreatedBy      string              `json:"createdBy,omitempty" bson:"createdBy,omitempty"` // ID of the user who queued the job.
+	InputFileID    *primitive.ObjectID `json:"-" bson:"inputFileId,omitempty"`
+	ResultFileID   *primitive.ObjectID `json:"-" bson:"resultFileId,omitempty"`
+	Processed      int64               `json:"processed" bson:"processed"`
+	Counts         map[string]int64    `json:"counts,omitempty" bson:"counts,omitempty"`
+	Errors         []JobError          `json:"errors,omitempty" bson:"errors,omitempty"`
+	Error          string              `json:"error,omitempty" bson:"error,omitempty"`
+	Attempts       int                 `json:"attempts" bson:"attempts"`
+	LeaseOwner     string              `json:"-" bson:"leaseOwner,omitempty"`
+	LeaseExpiresAt *time.Time          `json:"-" bson:"leaseExpiresAt,omitempty"`
+	CreatedAt      time.Time           `json:"createdAt" bson:"createdAt"`
+	StartedAt      *time.Time          `json:"startedAt,omitempty" bson:"startedAt,omitempty"`
+	FinishedAt     *time.Time          `json:"finishedAt,omitempty" bson:"finishedAt,omitempty"`
+}
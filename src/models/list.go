@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// List types. Every user has a to-read and a favorites shelf and can add custom
+// lists; collections are public lists curated by staff.
+const (
+	ListTypeToRead     = "to_read"
+	ListTypeFavorites  = "favorites"
+	ListTypeCustom     = "custom"
+	ListTypeCollection = "collection"
+)
+
+// List visibilities. Link lists can be read by anyone holding the share link.
+const (
+	ListVisibilityPrivate = "private"
+	ListVisibilityLink    = "link"
+	ListVisibilityPublic  = "public"
+)
+
+// List is an ordered set of books. BookIDs is kept free of duplicates and in the
+// order the owner chose.
+type List struct {
+	ID          primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	OwnerID     string               `json:"ownerId" bson:"ownerId"`
+	Type        string               `json:"type" bson:"type"`
+	Name        string               `json:"name" bson:"name"`
+	Slug        string               `json:"slug" bson:"slug"` // Unique per owner.
+	Description string               `json:"description,omitempty" bson:"description,omitempty"`
+	Visibility  string               `json:"visibility" bson:"visibility"`
+	ShareToken  string               `json:"shareToken,omitempty" bson:"shareToken,omitempty"`
+	Tag         string               `json:"tag,omitempty" bson:"tag,omitempty"` // Collections only: applied to every book in the collection.
+	BookIDs     []primitive.ObjectID `json:"bookIds" bson:"bookIds"`
+	Books       []Book               `json:"books,omitempty" bson:"-"` // Populated when a single list is read.
+	CreatedAt   time.Time            `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time            `json:"updatedAt" bson:"updatedAt"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Loan statuses.
+const (
+	LoanStatusActive   = "active"
+	LoanStatusReturned = "returned"
+)
+
+// Loan records one checkout of a copy. Role is the borrower's role at checkout and
+// decides the loan period and how often the loan can be renewed.
+type Loan struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	CopyID       primitive.ObjectID `json:"copyId" bson:"copyId"`
+	BookID       primitive.ObjectID `json:"bookId" bson:"bookId"`
+	UserID       string             `json:"userId" bson:"userId"`
+	Role         string             `json:"role" bson:"role"`
+	Status       string             `json:"status" bson:"status"`
+	CheckedOutAt time.Time          `json:"checkedOutAt" bson:"checkedOutAt"`
+	DueAt        time.Time          `json:"dueAt" bson:"dueAt"`
+	Renewals     int                `json:"renewals" bson:"renewals"`
+	ReturnedAt   *time.Time         `json:"returnedAt,omitempty" bson:"returnedAt,omitempty"`
+	CheckedOutBy string             `json:"checkedOutBy,omitempty" bson:"checkedOutBy,omitempty"` // Staff member who checked the copy out for the borrower.
+	FineAccrued  int64              `json:"fineAccrued,omitempty" bson:"fineAccrued,omitempty"`   // Overdue fine charged so far, in cents.
+}
+
+// Patron keeps per-user circulation counters. ID is the user ID from the gateway.
+type Patron struct {
+	ID          string    `json:"id" bson:"_id"`
+	Role        string    `json:"role,omitempty" bson:"role,omitempty"` // Role the user last borrowed with; sets the policy of loans staff make for them.
+	ActiveLoans int       `json:"activeLoans" bson:"activeLoans"`
+	FinesOwed   int64     `json:"finesOwed" bson:"finesOwed"` // Outstanding fines in cents.
+	CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" bson:"updatedAt"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Review is a reader's rating of a book, with optional text. Each user has at most
+// one review per book.
+type Review struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	BookID    primitive.ObjectID `json:"bookId" bson:"bookId"`
+	UserID    string             `json:"userId" bson:"userId"`
+	Rating    int                `json:"rating" bson:"rating"` // 1 to 5.
+	Title     string             `json:"title,omitempty" bson:"title,omitempty"`
+	Body      string             `json:"body,omitempty" bson:"body,omitempty"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
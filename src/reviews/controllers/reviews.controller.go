@@ -0,0 +1,79 @@
+package reviewsController
+
+import (
+	"fiber-app/src/auth"
+	"fiber-app/src/reviews/dtos"
+	reviewService "fiber-app/src/reviews/services"
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReviewController struct {
+	reviewService *reviewService.ReviewService
+}
+
+func NewReviewController() *ReviewController {
+	return &ReviewController{
+		reviewService: reviewService.NewReviewService(),
+	}
+}
+
+// GetBookReviews lists the reviews of the book in the :id param, one page at a time.
+func (rc *ReviewController) GetBookReviews(c *fiber.Ctx) error {
+	filter := new(dtos.ReviewFilter)
+	if err := utils.ParseQuery(c, filter); err != nil {
+		return err
+	}
+
+	page, err := rc.reviewService.GetBookReviews(c.UserContext(), c.Params("id"), filter)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": page})
+}
+
+func (rc *ReviewController) GetReview(c *fiber.Ctx) error {
+	review, err := rc.reviewService.GetReviewByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"data": review})
+}
+
+// CreateBookReview posts the caller's review of the book in the :id param.
+func (rc *ReviewController) CreateBookReview(c *fiber.Ctx) error {
+	dto := new(dtos.CreateDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	review, err := rc.reviewService.CreateReview(c.UserContext(), auth.CurrentUser(c), c.Params("id"), dto)
+	if err != nil {
+		return err
+	}
+
+	c.Location("/reviews/" + review.ID.Hex())
+	return c.Status(201).JSON(fiber.Map{"result": review})
+}
+
+func (rc *ReviewController) UpdateReview(c *fiber.Ctx) error {
+	dto := new(dtos.UpdateDTO)
+	if err := utils.ParseBody(c, dto); err != nil {
+		return err
+	}
+
+	review, err := rc.reviewService.UpdateReview(c.UserContext(), auth.CurrentUser(c), c.Params("id"), dto)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": review})
+}
+
+func (rc *ReviewController) DeleteReview(c *fiber.Ctx) error {
+	review, err := rc.reviewService.DeleteReview(c.UserContext(), auth.CurrentUser(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(fiber.Map{"result": review})
+}
@@ -0,0 +1,43 @@
+package dtos
+
+import (
+	"fiber-app/src/models"
+	"fiber-app/src/utils"
+)
+
+// CreateDTO represents the structure for reviewing a book.
+type CreateDTO struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Title  string `json:"title,omitempty" validate:"omitempty,max=200"`
+	Body   string `json:"body,omitempty" validate:"omitempty,max=10000"`
+}
+
+// UpdateDTO represents the structure for editing a review.
+type UpdateDTO struct {
+	Rating int    `json:"rating,omitempty" validate:"omitempty,min=1,max=5"`
+	Title  string `json:"title,omitempty" validate:"omitempty,max=200"`
+	Body   string `json:"body,omitempty" validate:"omitempty,max=10000"`
+}
+
+// ReviewFilter holds the query parameters of the review list endpoint.
+type ReviewFilter struct {
+	Sort     string `query:"sort"` // newest (default), oldest, highest or lowest.
+	Page     int64  `query:"page"`
+	PageSize int64  `query:"pageSize"`
+}
+
+// ReviewPage is one page of a book's reviews.
+type ReviewPage struct {
+	Total    int64           `json:"total"`
+	Page     int64           `json:"page"`
+	PageSize int64           `json:"pageSize"`
+	Items    []models.Review `json:"items"`
+}
+
+func (dto *CreateDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
+
+func (dto *UpdateDTO) Validate() error {
+	return utils.ValidateStruct(dto)
+}
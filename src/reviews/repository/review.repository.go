@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReviewRepository interface {
+	GetReviews(ctx context.Context, filter interface{}, page int64, pageSize int64, sort bson.D) ([]models.Review, int64, error)
+	GetReviewByID(ctx context.Context, id string) (*models.Review, error)
+	CreateReview(ctx context.Context, review *models.Review) (*mongo.InsertOneResult, error)
+	UpdateReview(ctx context.Context, filter interface{}, set bson.M) (*models.Review, error)
+	DeleteReview(ctx context.Context, filter interface{}) (*models.Review, error)
+	BookExists(ctx context.Context, bookID primitive.ObjectID) (bool, error)
+	AdjustBookRating(ctx context.Context, bookID primitive.ObjectID, count int, sum int) error
+	WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error)
+}
+
+type reviewRepository struct {
+	commonRepo *common.CommonRepository
+	booksRepo  *common.CommonRepository
+}
+
+// NewReviewRepository takes the books collection as well because every review
+// change is mirrored in its book's rating aggregates.
+func NewReviewRepository(collection *mongo.Collection, books *mongo.Collection) ReviewRepository {
+	return &reviewRepository{
+		commonRepo: common.NewCommonRepository(collection),
+		booksRepo:  common.NewCommonRepository(books),
+	}
+}
+
+func (r *reviewRepository) GetReviews(ctx context.Context, filter interface{}, page int64, pageSize int64, sort bson.D) ([]models.Review, int64, error) {
+	reviews := []models.Review{}
+	total, err := r.commonRepo.Paginate(ctx, filter, &reviews, page, pageSize, sort)
+	return reviews, total, err
+}
+
+func (r *reviewRepository) GetReviewByID(ctx context.Context, id string) (*models.Review, error) {
+	objectID, err := r.commonRepo.ConvertID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var review models.Review
+	err = r.commonRepo.FindOne(ctx, bson.M{"_id": objectID}, &review)
+	return &review, err
+}
+
+func (r *reviewRepository) CreateReview(ctx context.Context, review *models.Review) (*mongo.InsertOneResult, error) {
+	return r.commonRepo.InsertOne(ctx, review)
+}
+
+// UpdateReview sets fields on the review matching filter and returns the review as
+// it was before, so the caller can see the old rating. It returns
+// mongo.ErrNoDocuments when nothing matched.
+func (r *reviewRepository) UpdateReview(ctx context.Context, filter interface{}, set bson.M) (*models.Review, error) {
+	res, err := r.commonRepo.FindAndModify(ctx, filter, bson.M{"$set": set}, options.FindOneAndUpdate().SetReturnDocument(options.Before))
+	if err != nil {
+		return nil, err
+	}
+
+	var review models.Review
+	err = res.Decode(&review)
+	return &review, err
+}
+
+// DeleteReview removes the review matching filter and returns it. It returns
+// mongo.ErrNoDocuments when nothing matched.
+func (r *reviewRepository) DeleteReview(ctx context.Context, filter interface{}) (*models.Review, error) {
+	res, err := r.commonRepo.FindAndDelete(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var review models.Review
+	err = res.Decode(&review)
+	return &review, err
+}
+
+func (r *reviewRepository) BookExists(ctx context.Context, bookID primitive.ObjectID) (bool, error) {
+	return r.booksRepo.Exists(ctx, bson.M{"_id": bookID, "deletedAt": bson.M{"$exists": false}})
+}
+
+// AdjustBookRating adds count and sum to the book's rating count and sum and
+// recomputes the average from them, all in one update so readers never see the
+// three out of step.
+func (r *reviewRepository) AdjustBookRating(ctx context.Context, bookID primitive.ObjectID, count int, sum int) error {
+	if count == 0 && sum == 0 {
+		return nil
+	}
+	_, err := r.booksRepo.UpdateOneRaw(ctx, bson.M{"_id": bookID}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"ratingCount": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$ratingCount", 0}}, count}},
+			"ratingSum":   bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$ratingSum", 0}}, sum}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"ratingAverage": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{"$ratingCount", 0}},
+				bson.M{"$divide": bson.A{"$ratingSum", "$ratingCount"}},
+				0,
+			}},
+		}}},
+	})
+	return err
+}
+
+func (r *reviewRepository) WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error)) (interface{}, error) {
+	return r.commonRepo.WithTransaction(ctx, fn)
+}
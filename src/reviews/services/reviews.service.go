@@ -0,0 +1,206 @@
+package reviewService
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"fiber-app/src/auth"
+	"fiber-app/src/common"
+	"fiber-app/src/models"
+	"fiber-app/src/reviews/dtos"
+	"fiber-app/src/reviews/repository"
+	"fiber-app/src/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultReviewPageSize = 20
+	maxReviewPageSize     = 100
+)
+
+// reviewSorts are the orders the review list supports. Ties fall back to newest first.
+var reviewSorts = map[string]bson.D{
+	"newest":  {{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+	"oldest":  {{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}},
+	"highest": {{Key: "rating", Value: -1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+	"lowest":  {{Key: "rating", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+}
+
+type ReviewService struct {
+	repo repository.ReviewRepository
+}
+
+// NewReviewService initializes the repository and returns a new ReviewService instance.
+func NewReviewService() *ReviewService {
+	repo := repository.NewReviewRepository(common.GetDBCollection("reviews"), common.GetDBCollection("books"))
+	return &ReviewService{repo: repo}
+}
+
+// GetBookReviews returns one page of a book's reviews in the requested order.
+func (s *ReviewService) GetBookReviews(ctx context.Context, bookID string, filter *dtos.ReviewFilter) (*dtos.ReviewPage, error) {
+	objectID, err := primitive.ObjectIDFromHex(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	sortKey := filter.Sort
+	if sortKey == "" {
+		sortKey = "newest"
+	}
+	sort, ok := reviewSorts[sortKey]
+	if !ok {
+		return nil, utils.Validation("sort must be one of newest, oldest, highest, lowest")
+	}
+
+	page, pageSize := filter.Page, filter.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultReviewPageSize
+	}
+	if pageSize > maxReviewPageSize {
+		pageSize = maxReviewPageSize
+	}
+
+	reviews, total, err := s.repo.GetReviews(ctx, bson.M{"bookId": objectID}, page, pageSize, sort)
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.ReviewPage{Total: total, Page: page, PageSize: pageSize, Items: reviews}, nil
+}
+
+func (s *ReviewService) GetReviewByID(ctx context.Context, id string) (*models.Review, error) {
+	return s.repo.GetReviewByID(ctx, id)
+}
+
+// CreateReview records the user's review of a book and adds its rating to the
+// book's aggregates in the same transaction. A user can review a book only once;
+// they edit that review afterwards.
+func (s *ReviewService) CreateReview(ctx context.Context, user *auth.User, bookID string, dto *dtos.CreateDTO) (*models.Review, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+	bookObjectID, err := primitive.ObjectIDFromHex(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	review := &models.Review{
+		ID:        primitive.NewObjectID(),
+		BookID:    bookObjectID,
+		UserID:    user.ID,
+		Rating:    dto.Rating,
+		Title:     strings.TrimSpace(dto.Title),
+		Body:      strings.TrimSpace(dto.Body),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	_, err = s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		exists, err := s.repo.BookExists(sessCtx, bookObjectID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, mongo.ErrNoDocuments
+		}
+		if _, err := s.repo.CreateReview(sessCtx, review); err != nil {
+			return nil, err
+		}
+		return nil, s.repo.AdjustBookRating(sessCtx, bookObjectID, 1, review.Rating)
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, utils.Conflict("review_exists", "you have already reviewed this book")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// UpdateReview edits the user's own review. A changed rating moves the book's
+// aggregates by the difference.
+func (s *ReviewService) UpdateReview(ctx context.Context, user *auth.User, id string, dto *dtos.UpdateDTO) (*models.Review, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, utils.ValidationFailed(err)
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{"updatedAt": time.Now().UTC()}
+	if dto.Rating != 0 {
+		set["rating"] = dto.Rating
+	}
+	if dto.Title != "" {
+		set["title"] = strings.TrimSpace(dto.Title)
+	}
+	if dto.Body != "" {
+		set["body"] = strings.TrimSpace(dto.Body)
+	}
+
+	_, err = s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		previous, err := s.repo.UpdateReview(sessCtx, bson.M{"_id": objectID, "userId": user.ID}, set)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, s.missingOrForbidden(sessCtx, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if dto.Rating == 0 {
+			return nil, nil
+		}
+		return nil, s.repo.AdjustBookRating(sessCtx, previous.BookID, 0, dto.Rating-previous.Rating)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetReviewByID(ctx, id)
+}
+
+// DeleteReview removes a review and takes its rating off the book's aggregates.
+// Users delete their own reviews; staff can delete any.
+func (s *ReviewService) DeleteReview(ctx context.Context, user *auth.User, id string) (*models.Review, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"_id": objectID}
+	if !user.IsStaff() {
+		filter["userId"] = user.ID
+	}
+
+	deleted, err := s.repo.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		review, err := s.repo.DeleteReview(sessCtx, filter)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, s.missingOrForbidden(sessCtx, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return review, s.repo.AdjustBookRating(sessCtx, review.BookID, -1, -review.Rating)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted.(*models.Review), nil
+}
+
+// missingOrForbidden tells apart a review that does not exist from one that
+// belongs to someone else.
+func (s *ReviewService) missingOrForbidden(ctx context.Context, id string) error {
+	if _, err := s.repo.GetReviewByID(ctx, id); err != nil {
+		return err
+	}
+	return utils.Forbidden("review belongs to another user")
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"fiber-app/src/auth"
+	authorsController "fiber-app/src/authors/controllers"
+	booksController "fiber-app/src/books/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AddAuthorGroup(app *fiber.App) {
+	authorController := authorsController.NewAuthorController()
+	bookController := booksController.NewBookController()
+	authorGroup := app.Group("/authors")
+	staffOnly := auth.RequireRole(auth.RoleStaff, auth.RoleAdmin)
+
+	authorGroup.Get("/", authorController.GetAuthors)                    // Fetch all authors
+	authorGroup.Get("/:id", authorController.GetAuthor)                  // Fetch a specific author by ID
+	authorGroup.Get("/:id/books", bookController.GetAuthorBooks)         // Fetch the books by an author
+	authorGroup.Post("/", staffOnly, authorController.CreateAuthor)      // Create a new author
+	authorGroup.Put("/:id", staffOnly, authorController.UpdateAuthor)    // Update an author by ID
+	authorGroup.Delete("/:id", staffOnly, authorController.DeleteAuthor) // Delete an author that no book references
+}
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"fiber-app/src/auth"
 	booksController "fiber-app/src/books/controllers"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,12 +10,26 @@ import (
 func AddBookGroup(app *fiber.App) {
 	// Initialize the controller and routes
 	bookController := booksController.NewBookController()
+	bookController.RegisterJobHandlers()
 	bookGroup := app.Group("/books")
+	staffOnly := auth.RequireRole(auth.RoleStaff, auth.RoleAdmin)
+	requireUser := auth.RequireUser()
 
 	// Add route handlers from the booksController
-	bookGroup.Get("/", bookController.GetBooks)         // Fetch all books
-	bookGroup.Get("/:id", bookController.GetBook)       // Fetch a specific book by ID
-	bookGroup.Post("/", bookController.CreateBook)      // Create a new book
-	bookGroup.Put("/:id", bookController.UpdateBook)    // Update a book by ID
-	bookGroup.Delete("/:id", bookController.DeleteBook) // Delete a book by ID
+	bookGroup.Get("/", bookController.GetBooks)                             // Fetch all books
+	bookGroup.Get("/export", bookController.ExportBooks)                    // Stream books as CSV, NDJSON or XLSX
+	bookGroup.Get("/facets", bookController.GetBookFacets)                  // Count matching books by author, decade, genre and language
+	bookGroup.Get("/suggest", bookController.GetSuggestions)                // Complete search box text with titles and authors
+	bookGroup.Get("/duplicates", staffOnly, bookController.GetDuplicates)   // Group books that look like duplicates
+	bookGroup.Get("/:id", bookController.GetBook)                           // Fetch a specific book by ID
+	bookGroup.Post("/", staffOnly, bookController.CreateBook)               // Create a new book
+	bookGroup.Post("/import", requireUser, bookController.ImportBooks)      // Queue an import of a CSV or NDJSON file
+	bookGroup.Post("/export", requireUser, bookController.ExportBooksAsync) // Queue an export for later download
+	bookGroup.Post("/batch", staffOnly, bookController.BatchBooks)          // Create, update and delete books in one request
+	bookGroup.Post("/merge", staffOnly, bookController.MergeBooks)          // Merge duplicate books into one
+	bookGroup.Put("/:id", staffOnly, bookController.UpdateBook)             // Update a book by ID
+	bookGroup.Delete("/:id", staffOnly, bookController.DeleteBook)          // Delete a book by ID
+	bookGroup.Get("/:id/cover", bookController.GetCover)                    // Serve a book's cover, optionally as a ?size= thumbnail
+	bookGroup.Put("/:id/cover", staffOnly, bookController.UploadCover)      // Upload a JPEG, PNG or WebP cover image
+	bookGroup.Delete("/:id/cover", staffOnly, bookController.DeleteCover)   // Remove a book's cover
 }
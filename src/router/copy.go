@@ -0,0 +1,21 @@
+package router
+
+import (
+	"fiber-app/src/auth"
+	copiesController "fiber-app/src/copies/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AddCopyGroup(app *fiber.App) {
+	copyController := copiesController.NewCopyController()
+	staffOnly := auth.RequireRole(auth.RoleStaff, auth.RoleAdmin)
+
+	app.Get("/books/:id/copies", copyController.GetBookCopies)              // Fetch the copies of a book
+	app.Post("/books/:id/copies", staffOnly, copyController.CreateBookCopy) // Add a copy of a book
+
+	copyGroup := app.Group("/copies")
+	copyGroup.Get("/:id", copyController.GetCopy)                  // Fetch a specific copy by ID
+	copyGroup.Put("/:id", staffOnly, copyController.UpdateCopy)    // Update a copy's location, condition or status
+	copyGroup.Delete("/:id", staffOnly, copyController.DeleteCopy) // Delete a copy that is not on loan or on hold
+}
@@ -0,0 +1,15 @@
+package router
+
+import (
+	healthController "fiber-app/src/health/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AddHealthGroup(app *fiber.App) {
+	probeController := healthController.NewHealthController()
+
+	app.Get("/healthz", probeController.Liveness) // Process is up
+	app.Get("/readyz", probeController.Readiness) // Ready for traffic: not draining and every check passes
+	app.Get("/health", probeController.Report)    // Status and latency of every dependency
+}
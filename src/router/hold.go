@@ -0,0 +1,20 @@
+package router
+
+import (
+	"fiber-app/src/auth"
+	holdsController "fiber-app/src/holds/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AddHoldGroup(app *fiber.App) {
+	holdController := holdsController.NewHoldController()
+	holdController.RegisterTasks()
+	holdController.RegisterReleaser()
+	holdGroup := app.Group("/holds", auth.RequireUser())
+
+	holdGroup.Get("/", holdController.GetHolds)              // Fetch holds; members only see their own
+	holdGroup.Get("/:id", holdController.GetHold)            // Fetch a specific hold by ID
+	holdGroup.Post("/", holdController.PlaceHold)            // Join the queue for a book
+	holdGroup.Post("/:id/cancel", holdController.CancelHold) // Leave the queue or give up a ready copy
+}
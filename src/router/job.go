@@ -0,0 +1,16 @@
+package router
+
+import (
+	"fiber-app/src/auth"
+	jobsController "fiber-app/src/jobs/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AddJobGroup(app *fiber.App) {
+	jobController := jobsController.NewJobController()
+	jobGroup := app.Group("/jobs", auth.RequireUser())
+
+	jobGroup.Get("/:id", jobController.GetJob)                     // Fetch the status and progress of a job you queued, or any as staff
+	jobGroup.Get("/:id/download", jobController.DownloadJobResult) // Download the result of a finished job you queued, or any as staff
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"fiber-app/src/auth"
+	listsController "fiber-app/src/lists/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AddListGroup(app *fiber.App) {
+	listController := listsController.NewListController()
+	requireUser := auth.RequireUser()
+	staffOnly := auth.RequireRole(auth.RoleStaff, auth.RoleAdmin)
+
+	app.Get("/collections", listController.GetCollections)               // Fetch the curated collections
+	app.Post("/collections", staffOnly, listController.CreateCollection) // Create a curated collection
+	app.Get("/shared/lists/:token", listController.GetSharedList)        // Open a list shared by link
+
+	listGroup := app.Group("/lists")
+	listGroup.Get("/", requireUser, listController.GetMyLists)                     // Fetch your shelves and lists
+	listGroup.Get("/:id", listController.GetList)                                  // Fetch a list you own, or a public one
+	listGroup.Post("/", requireUser, listController.CreateList)                    // Create a custom list
+	listGroup.Put("/:id", requireUser, listController.UpdateList)                  // Rename a list or change who can see it
+	listGroup.Delete("/:id", requireUser, listController.DeleteList)               // Delete a custom list or collection
+	listGroup.Post("/:id/books", requireUser, listController.AddBook)              // Add a book to a list
+	listGroup.Put("/:id/books", requireUser, listController.ReorderBooks)          // Reorder the books on a list
+	listGroup.Delete("/:id/books/:bookId", requireUser, listController.RemoveBook) // Remove a book from a list
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"fiber-app/src/auth"
+	loansController "fiber-app/src/loans/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AddLoanGroup(app *fiber.App) {
+	loanController := loansController.NewLoanController()
+	loanGroup := app.Group("/loans", auth.RequireUser())
+
+	loanGroup.Get("/", loanController.GetLoans)              // Fetch loans; members only see their own
+	loanGroup.Get("/:id", loanController.GetLoan)            // Fetch a specific loan by ID
+	loanGroup.Post("/", loanController.Checkout)             // Check out a copy
+	loanGroup.Post("/:id/return", loanController.ReturnLoan) // Return a checked out copy
+	loanGroup.Post("/:id/renew", loanController.RenewLoan)   // Extend a loan's due date
+}
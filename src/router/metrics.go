@@ -0,0 +1,12 @@
+package router
+
+import (
+	"fiber-app/src/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+func AddMetricsGroup(app *fiber.App) {
+	app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler())) // Prometheus scrape endpoint
+}
@@ -0,0 +1,20 @@
+package router
+
+import (
+	"fiber-app/src/auth"
+	reviewsController "fiber-app/src/reviews/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AddReviewGroup(app *fiber.App) {
+	reviewController := reviewsController.NewReviewController()
+
+	app.Get("/books/:id/reviews", reviewController.GetBookReviews)                        // Fetch a book's reviews
+	app.Post("/books/:id/reviews", auth.RequireUser(), reviewController.CreateBookReview) // Review a book
+
+	reviewGroup := app.Group("/reviews")
+	reviewGroup.Get("/:id", reviewController.GetReview)                           // Fetch a specific review by ID
+	reviewGroup.Put("/:id", auth.RequireUser(), reviewController.UpdateReview)    // Edit your own review
+	reviewGroup.Delete("/:id", auth.RequireUser(), reviewController.DeleteReview) // Delete your own review, or any as staff
+}
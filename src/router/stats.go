@@ -0,0 +1,14 @@
+package router
+
+import (
+	booksController "fiber-app/src/books/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AddStatsGroup(app *fiber.App) {
+	bookController := booksController.NewBookController()
+	statsGroup := app.Group("/stats")
+
+	statsGroup.Get("/books", bookController.GetBookStats) // Catalog totals, books per year and top authors
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"fiber-app/src/auth"
+	finesController "fiber-app/src/fines/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AddUserGroup(app *fiber.App) {
+	fineController := finesController.NewFineController()
+	fineController.RegisterTasks()
+	staffOnly := auth.RequireRole(auth.RoleStaff, auth.RoleAdmin)
+	adminOnly := auth.RequireRole(auth.RoleAdmin)
+	userGroup := app.Group("/users", auth.RequireUser())
+
+	userGroup.Get("/me/fines", fineController.GetMyFines)                    // Fetch the caller's fines
+	userGroup.Get("/:id/fines", staffOnly, fineController.GetUserFines)      // Fetch a user's fines
+	userGroup.Post("/:id/fines/pay", adminOnly, fineController.PayFines)     // Record a payment of a user's fines
+	userGroup.Post("/:id/fines/waive", adminOnly, fineController.WaiveFines) // Waive some or all of a user's fines
+}
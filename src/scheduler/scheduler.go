@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"fiber-app/src/logging"
+)
+
+// Task is work that runs periodically inside the process. Every instance runs every
+// task, so a task must be safe to run concurrently with itself on other instances.
+type Task struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+var (
+	tasksMu sync.Mutex
+	tasks   []Task
+)
+
+var logger = logging.For("scheduler")
+
+// Register adds a task to the schedule. Modules register their tasks while routes
+// are being set up, before Start.
+func Register(name string, interval time.Duration, run func(ctx context.Context) error) {
+	tasksMu.Lock()
+	defer tasksMu.Unlock()
+	tasks = append(tasks, Task{Name: name, Interval: interval, Run: run})
+}
+
+// Scheduler runs the registered tasks, each on its own goroutine and ticker.
+type Scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a scheduler. It does nothing until Start.
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{ctx: ctx, cancel: cancel}
+}
+
+// Start launches every task registered so far. Each task first runs one interval
+// after Start.
+func (s *Scheduler) Start() {
+	tasksMu.Lock()
+	defer tasksMu.Unlock()
+
+	for _, task := range tasks {
+		s.wg.Add(1)
+		go s.loop(task)
+	}
+}
+
+// Stop cancels running tasks and waits for them to return, or for ctx to expire.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(task Task) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(task.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(task)
+		}
+	}
+}
+
+func (s *Scheduler) run(task Task) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("scheduled task panicked", "task", task.Name, "panic", r)
+		}
+	}()
+
+	if err := task.Run(s.ctx); err != nil && s.ctx.Err() == nil {
+		logger.Error("running scheduled task", "task", task.Name, logging.Err(err))
+	}
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"fiber-app/src/config"
+)
+
+// ErrNotFound is returned when no blob is stored under a key.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobInfo describes a stored blob.
+type BlobInfo struct {
+	Key         string
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+	ETag        string // Changes whenever the blob is replaced.
+}
+
+// BlobStore keeps binary files such as images under string keys. Putting a key
+// that already exists replaces its blob.
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, r io.Reader) (*BlobInfo, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobStore returns the store for the named bucket. The blob config picks the
+// backend: "gridfs" (the default) keeps blobs in the GridFS bucket of that name and
+// "local" keeps them in a directory of that name under the configured directory.
+func NewBlobStore(bucket string) (BlobStore, error) {
+	cfg := config.Get().Blob
+	switch cfg.Store {
+	case "", "gridfs":
+		return NewGridFSStore(bucket), nil
+	case "local":
+		return NewLocalStore(filepath.Join(cfg.Dir, bucket))
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q", cfg.Store)
+	}
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"fiber-app/src/common"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GridFSStore keeps blobs in a GridFS bucket, using the key as the file name. Each
+// Put uploads a new revision and then removes the older ones, so readers never see
+// a half-written blob.
+type GridFSStore struct {
+	bucket string
+}
+
+func NewGridFSStore(bucket string) *GridFSStore {
+	return &GridFSStore{bucket: bucket}
+}
+
+func (s *GridFSStore) Put(ctx context.Context, key, contentType string, r io.Reader) (*BlobInfo, error) {
+	bucket, err := common.GetGridFSBucket(s.bucket)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := bucket.SetWriteDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	counter := &countingReader{r: r}
+	fileID, err := bucket.UploadFromStream(key, counter, options.GridFSUpload().SetMetadata(bson.M{"contentType": contentType}))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.deleteRevisions(ctx, bucket, bson.M{"filename": key, "_id": bson.M{"$ne": fileID}}); err != nil {
+		return nil, err
+	}
+
+	return &BlobInfo{Key: key, ContentType: contentType, Size: counter.n, ModTime: fileID.Timestamp(), ETag: fileID.Hex()}, nil
+}
+
+func (s *GridFSStore) Open(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	bucket, err := common.GetGridFSBucket(s.bucket)
+	if err != nil {
+		return nil, nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := bucket.SetReadDeadline(deadline); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The latest revision is opened by default.
+	stream, err := bucket.OpenDownloadStreamByName(key)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return stream, fileInfo(key, stream.GetFile()), nil
+}
+
+func (s *GridFSStore) Delete(ctx context.Context, key string) error {
+	bucket, err := common.GetGridFSBucket(s.bucket)
+	if err != nil {
+		return err
+	}
+	return s.deleteRevisions(ctx, bucket, bson.M{"filename": key})
+}
+
+func (s *GridFSStore) deleteRevisions(ctx context.Context, bucket *gridfs.Bucket, filter bson.M) error {
+	cursor, err := bucket.FindContext(ctx, filter)
+	if err != nil {
+		return err
+	}
+	var files []gridfs.File
+	if err := cursor.All(ctx, &files); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := bucket.DeleteContext(ctx, file.ID); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func fileInfo(key string, file *gridfs.File) *BlobInfo {
+	info := &BlobInfo{Key: key, Size: file.Length, ModTime: file.UploadDate}
+	if id, ok := file.ID.(primitive.ObjectID); ok {
+		info.ETag = id.Hex()
+	}
+	if file.Metadata != nil {
+		if value, err := file.Metadata.LookupErr("contentType"); err == nil {
+			info.ContentType, _ = value.StringValueOK()
+		}
+	}
+	return info
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
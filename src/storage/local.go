@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LocalStore keeps blobs as files under a directory. The content type of each blob
+// is kept next to it in a ".meta" file.
+type LocalStore struct {
+	dir string
+}
+
+type localMeta struct {
+	ContentType string `json:"contentType"`
+}
+
+// NewLocalStore creates dir if it does not exist yet.
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// path maps a key to a file under the store's directory. Keys may use "/" to group
+// blobs but cannot climb out of the directory.
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) || strings.HasSuffix(clean, ".meta") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+// Put writes to a temporary file first and renames it into place, so readers see
+// either the old blob or the new one.
+func (s *LocalStore) Put(ctx context.Context, key, contentType string, r io.Reader) (*BlobInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	meta, err := json.Marshal(localMeta{ContentType: contentType})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path+".meta", meta, 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return localInfo(key, contentType, stat), nil
+}
+
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	var meta localMeta
+	if raw, err := os.ReadFile(path + ".meta"); err == nil {
+		_ = json.Unmarshal(raw, &meta)
+	}
+	return file, localInfo(key, meta.ContentType, stat), nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	for _, p := range []string{path, path + ".meta"} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func localInfo(key, contentType string, stat fs.FileInfo) *BlobInfo {
+	return &BlobInfo{
+		Key:         key,
+		ContentType: contentType,
+		Size:        stat.Size(),
+		ModTime:     stat.ModTime().UTC(),
+		ETag:        strconv.FormatInt(stat.ModTime().UnixNano(), 36) + "-" + strconv.FormatInt(stat.Size(), 36),
+	}
+}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"fiber-app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span for every request, continuing the trace of an
+// incoming traceparent header. Handlers reach the span through c.UserContext(), so
+// they must pass that, not c.Context(), to the services they call. Errors are
+// rendered here with the app's ErrorHandler so the span records the final status.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{&c.Request().Header})
+		ctx, span := Tracer().Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+				semconv.URLScheme(c.Protocol()),
+				semconv.UserAgentOriginal(c.Get(fiber.HeaderUserAgent)),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		if err := c.Next(); err != nil {
+			span.RecordError(err)
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := utils.RouteTemplate(c)
+		status := c.Response().StatusCode()
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+		return nil
+	}
+}
+
+// headerCarrier adapts fasthttp request headers to a propagation.TextMapCarrier.
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+func (h headerCarrier) Get(key string) string {
+	return string(h.header.Peek(key))
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
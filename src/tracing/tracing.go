@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// serviceName names the service in traces unless OTEL_SERVICE_NAME overrides it.
+const serviceName = "fiber-app"
+
+// Exporters OTEL_TRACES_EXPORTER can pick.
+const (
+	ExporterOTLP    = "otlp"
+	ExporterConsole = "console" // Pretty printed to stdout, for local use.
+	ExporterNone    = "none"
+)
+
+// Init installs the global tracer provider and the W3C trace context and baggage
+// propagators. OTEL_TRACES_EXPORTER picks the exporter; it defaults to otlp when
+// an OTLP endpoint is configured and to none otherwise. The OTLP exporter and the
+// sampler read the standard OTEL_EXPORTER_OTLP_* and OTEL_TRACES_SAMPLER variables.
+// The returned function flushes and stops the provider.
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var processor sdktrace.SpanProcessor
+	switch exporterName() {
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		processor = sdktrace.NewBatchSpanProcessor(exporter)
+	case ExporterConsole, "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, err
+		}
+		processor = sdktrace.NewSimpleSpanProcessor(exporter)
+	default:
+		// Spans stay non-recording, but incoming trace context still propagates.
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func exporterName() string {
+	if name := strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")); name != "" {
+		return name
+	}
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		return ExporterOTLP
+	}
+	return ExporterNone
+}
+
+// Tracer returns the app's tracer from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(serviceName)
+}
+
+// Start starts a span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
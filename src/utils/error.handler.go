@@ -1,27 +1,254 @@
 package utils
 
 import (
-	"github.com/go-playground/validator/v10"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
+
+	"fiber-app/src/i18n"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
-// // Error struct is used to represent custom errors with messages
+// Error is an error a client can act on. Err is a stable machine-readable code such
+// as "copy_unavailable" and Status the HTTP status it is reported with; 400 when
+// unset. Fields lists per-field validation failures.
 type Error struct {
-	Err   string `json:"error"`
-	Message string `json:"message"`
+	Status  int          `json:"-"`
+	Err     string       `json:"error"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
 }
 
 func (e *Error) Error() string {
 	return e.Message
 }
-// FormatValidationError formats the validation errors into a user-friendly message.
+
+// FieldError is one failed validation rule of a request field. Message is in English;
+// the ErrorHandler renders it again in the request's language.
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+
+	key string // Catalog key of Message; "validation." + Tag when empty.
+}
+
+// NewError returns an Error with the given status and code.
+func NewError(status int, code, message string) *Error {
+	return &Error{Status: status, Err: code, Message: message}
+}
+
+// BadRequest reports a request the client has to change before retrying.
+func BadRequest(code, message string) *Error {
+	return NewError(http.StatusBadRequest, code, message)
+}
+
+// Validation reports invalid input, optionally with the fields at fault.
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Status: http.StatusBadRequest, Err: "validation_failed", Message: message, Fields: fields}
+}
+
+// ValidationFailed turns the error returned by a DTO's Validate into a validation
+// Error listing every failed field.
+func ValidationFailed(err error) *Error {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return Validation(err.Error())
+	}
+
+	fields := make([]FieldError, len(errs))
+	for i, e := range errs {
+		fields[i] = newFieldError(e)
+	}
+	return Validation(FormatValidationError(err), fields...)
+}
+
+// NotFound reports a missing resource.
+func NotFound(message string) *Error {
+	return NewError(http.StatusNotFound, "not_found", message)
+}
+
+// Conflict reports a request that clashes with the resource's current state.
+func Conflict(code, message string) *Error {
+	return NewError(http.StatusConflict, code, message)
+}
+
+// Unauthorized reports a request without valid credentials.
+func Unauthorized(message string) *Error {
+	return NewError(http.StatusUnauthorized, "unauthorized", message)
+}
+
+// Forbidden reports a request the caller is not allowed to make.
+func Forbidden(message string) *Error {
+	return NewError(http.StatusForbidden, "forbidden", message)
+}
+
+// FormatValidationError formats the validation errors into an English message such
+// as "title must be at least 3 characters long; year is required".
 func FormatValidationError(err error) string {
 	var errorMsgs []string
-	if errs, ok := err.(validator.ValidationErrors); ok {
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) {
 		for _, e := range errs {
-			// Build a more user-friendly error message
-			errorMsgs = append(errorMsgs, "Field '"+e.Field()+"' failed validation on the '"+e.Tag()+"' tag")
+			errorMsgs = append(errorMsgs, newFieldError(e).Message)
+		}
+	}
+	return strings.Join(errorMsgs, "; ")
+}
+
+func newFieldError(e validator.FieldError) FieldError {
+	field := FieldError{Field: e.Field(), Tag: e.Tag(), Param: e.Param(), key: fieldKey(e)}
+	field.Message, _ = i18n.Lookup(i18n.Translator(i18n.Default), field.key, field.Field, field.Param)
+	return field
+}
+
+// fieldKey returns the catalog key of a failed rule. Size rules are worded by the
+// kind of value they apply to: characters, items or a number.
+func fieldKey(e validator.FieldError) string {
+	tag := e.Tag()
+	switch tag {
+	case "min", "gte", "max", "lte":
+		if tag == "gte" {
+			tag = "min"
+		} else if tag == "lte" {
+			tag = "max"
 		}
+		switch e.Kind() {
+		case reflect.String:
+			return "validation." + tag + ".string"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return "validation." + tag + ".items"
+		}
+		return "validation." + tag + ".number"
+	}
+	if _, ok := i18n.Lookup(i18n.Translator(i18n.Default), "validation."+tag); ok {
+		return "validation." + tag
+	}
+	return "validation.default"
+}
+
+// Problem is an RFC 7807 problem details body. Code and Errors are extensions
+// carrying the Error code and its field failures.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// MIMEProblemJSON is the content type of problem details bodies.
+const MIMEProblemJSON = "application/problem+json"
+
+// ErrorHandler is the app's fiber ErrorHandler. Handlers return errors instead of
+// writing error responses, and this renders them all as problem details in the
+// language the request's Accept-Language prefers. Errors it does not recognise are
+// logged with slog's default logger and reported as a bare 500, so internals do not
+// leak.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	e := toError(err)
+	if e.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(c.UserContext(), "unhandled error", "method", c.Method(), "path", c.OriginalURL(), "error", err)
+	}
+
+	trans := i18n.FromRequest(c)
+	problem := localizeProblem(trans, e)
+	problem.Instance = c.OriginalURL()
+	c.Set(fiber.HeaderContentLanguage, trans.Locale())
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return c.Status(e.Status).JSON(problem, MIMEProblemJSON)
+}
+
+// LocalizeError classifies err and renders it in trans's language as the
+// ErrorHandler would, for errors reported inside a response body instead of as the
+// response. Errors it does not recognise become a bare internal error, so callers
+// should log those themselves.
+func LocalizeError(trans ut.Translator, err error) Problem {
+	return localizeProblem(trans, toError(err))
+}
+
+// localizeProblem translates the title, the field messages and, when it is still the
+// catalog's English text for its code, the detail of e. Details written by services
+// carry specifics the catalogs do not, so those stay as they are.
+func localizeProblem(trans ut.Translator, e *Error) Problem {
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(e.Status),
+		Status: e.Status,
+		Detail: e.Message,
+		Code:   e.Err,
+	}
+	if title, ok := i18n.Lookup(trans, "status."+strconv.Itoa(e.Status)); ok {
+		problem.Title = title
+	}
+
+	if len(e.Fields) > 0 {
+		problem.Errors = make([]FieldError, len(e.Fields))
+		messages := make([]string, len(e.Fields))
+		for i, field := range e.Fields {
+			key := field.key
+			if key == "" {
+				key = "validation." + field.Tag
+			}
+			if message, ok := i18n.Lookup(trans, key, field.Field, field.Param); ok {
+				field.Message = message
+			}
+			problem.Errors[i] = field
+			messages[i] = field.Message
+		}
+		if e.Err == "validation_failed" {
+			problem.Detail = strings.Join(messages, "; ")
+		}
+		return problem
+	}
+
+	english, ok := i18n.Lookup(i18n.Translator(i18n.Default), "error."+e.Err)
+	if ok && english == e.Message {
+		problem.Detail, _ = i18n.Lookup(trans, "error."+e.Err)
+	}
+	return problem
+}
+
+// toError classifies any error returned by a handler.
+func toError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		if e.Status == 0 {
+			copied := *e
+			copied.Status = http.StatusBadRequest
+			return &copied
+		}
+		return e
+	}
+
+	var fiberErr *fiber.Error
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &fiberErr):
+		code := strings.ToLower(strings.ReplaceAll(http.StatusText(fiberErr.Code), " ", "_"))
+		return NewError(fiberErr.Code, code, fiberErr.Message)
+	case errors.As(err, &validationErrs):
+		return ValidationFailed(err)
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return NotFound("resource not found")
+	case errors.Is(err, primitive.ErrInvalidHex):
+		return BadRequest("invalid_id", "invalid id")
+	case mongo.IsDuplicateKeyError(err):
+		return Conflict("duplicate_key", "a resource with the same unique value already exists")
+	case errors.Is(err, context.DeadlineExceeded) || mongo.IsTimeout(err):
+		return NewError(http.StatusGatewayTimeout, "timeout", "the operation timed out")
 	}
-	return strings.Join(errorMsgs, ", ")
+	return NewError(http.StatusInternalServerError, "internal_error", "an unexpected error occurred")
 }
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"fiber-app/src/i18n"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type titleDTO struct {
+	Title string `json:"title" validate:"required,min=3"`
+	Year  int    `json:"year" validate:"required"`
+}
+
+func TestToError(t *testing.T) {
+	duplicate := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000 duplicate key error"}}}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"error without status", &Error{Err: "copy_unavailable", Message: "no copy"}, http.StatusBadRequest, "copy_unavailable"},
+		{"wrapped error", fmt.Errorf("checking out: %w", Conflict("loan_limit", "too many loans")), http.StatusConflict, "loan_limit"},
+		{"fiber error", fiber.NewError(http.StatusRequestEntityTooLarge, "body too large"), http.StatusRequestEntityTooLarge, "request_entity_too_large"},
+		{"validation errors", ValidateStruct(titleDTO{}), http.StatusBadRequest, "validation_failed"},
+		{"no documents", fmt.Errorf("finding book: %w", mongo.ErrNoDocuments), http.StatusNotFound, "not_found"},
+		{"invalid hex", primitive.ErrInvalidHex, http.StatusBadRequest, "invalid_id"},
+		{"duplicate key", duplicate, http.StatusConflict, "duplicate_key"},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "timeout"},
+		{"unknown", errors.New("dial tcp 10.0.0.1:27017: connection refused"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := toError(tt.err)
+			if e.Status != tt.status || e.Err != tt.code {
+				t.Errorf("toError(%v) = %d %q, want %d %q", tt.err, e.Status, e.Err, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestToErrorDoesNotChangeTheError(t *testing.T) {
+	original := &Error{Err: "copy_unavailable", Message: "no copy"}
+	if e := toError(original); e == original || original.Status != 0 {
+		t.Errorf("toError set the status on the error it was given")
+	}
+}
+
+func TestToErrorHidesInternalMessages(t *testing.T) {
+	e := toError(errors.New("dial tcp 10.0.0.1:27017: connection refused"))
+	if e.Message != "an unexpected error occurred" {
+		t.Errorf("internal error reported as %q", e.Message)
+	}
+}
+
+func TestLocalizeProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		err    *Error
+		title  string
+		detail string
+	}{
+		{"catalog message in english", "en", NotFound("resource not found"), "Not Found", "resource not found"},
+		{"catalog message translated", "es", NotFound("resource not found"), "No encontrado", "recurso no encontrado"},
+		{"service message kept", "es", Conflict("book_in_use", "book still has copies"), "Conflicto", "book still has copies"},
+		{"validation detail from fields", "es", ValidationFailed(ValidateStruct(titleDTO{Title: "ab", Year: 1990})), "Solicitud incorrecta", "title debe tener al menos 3 caracteres"},
+		{"unsupported locale", "fr", NotFound("resource not found"), "Not Found", "resource not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := localizeProblem(i18n.Translator(tt.locale), tt.err)
+			if problem.Status != tt.err.Status || problem.Code != tt.err.Err {
+				t.Errorf("problem = %d %q, want %d %q", problem.Status, problem.Code, tt.err.Status, tt.err.Err)
+			}
+			if problem.Title != tt.title {
+				t.Errorf("title = %q, want %q", problem.Title, tt.title)
+			}
+			if problem.Detail != tt.detail {
+				t.Errorf("detail = %q, want %q", problem.Detail, tt.detail)
+			}
+		})
+	}
+}
+
+func TestLocalizeProblemFields(t *testing.T) {
+	err := ValidationFailed(ValidateStruct(titleDTO{}))
+	problem := localizeProblem(i18n.Translator("es"), err)
+
+	want := []FieldError{
+		{Field: "title", Tag: "required", Message: "title es obligatorio"},
+		{Field: "year", Tag: "required", Message: "year es obligatorio"},
+	}
+	if len(problem.Errors) != len(want) {
+		t.Fatalf("got %d field errors, want %d: %+v", len(problem.Errors), len(want), problem.Errors)
+	}
+	for i, w := range want {
+		got := problem.Errors[i]
+		if got.Field != w.Field || got.Tag != w.Tag || got.Message != w.Message {
+			t.Errorf("field error %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if problem.Detail != "title es obligatorio; year es obligatorio" {
+		t.Errorf("detail = %q", problem.Detail)
+	}
+	// The Error itself keeps its English messages for logs.
+	if err.Fields[0].Message != "title is required" {
+		t.Errorf("English field message changed to %q", err.Fields[0].Message)
+	}
+}
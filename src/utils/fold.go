@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// FoldText reduces text to a search key: diacritics removed, lowercase and
+// punctuation collapsed to single spaces, so "Cien Años: de Soledad" becomes
+// "cien anos de soledad". Stored folded fields can then be searched with
+// case-sensitive, prefix-anchored regexes that use their index.
+func FoldText(text string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, text)
+	if err != nil {
+		folded = text
+	}
+
+	fields := strings.FieldsFunc(strings.ToLower(folded), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,24 @@
+package utils
+
+import "testing"
+
+func TestFoldText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Cien Años: de Soledad", "cien anos de soledad"},
+		{"  L'Étranger  ", "l etranger"},
+		{"Catch-22", "catch 22"},
+		{"Ça, c'est déjà vu!", "ca c est deja vu"},
+		{"ÜBER", "uber"},
+		{"---", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := FoldText(tt.text); got != tt.want {
+			t.Errorf("FoldText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package utils
+
+import "strings"
+
+// NormalizeISBN strips separators from an ISBN-10 or ISBN-13, checks its check digit
+// and returns the canonical 13-digit form. ISBN-10s are converted to their 978-
+// prefixed ISBN-13 equivalent so each book has exactly one stored representation.
+func NormalizeISBN(isbn string) (string, bool) {
+	var b strings.Builder
+	for _, r := range isbn {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == 'x' || r == 'X':
+			b.WriteRune('X')
+		case r == '-' || r == ' ':
+		default:
+			return "", false
+		}
+	}
+	digits := b.String()
+
+	switch len(digits) {
+	case 10:
+		if !validISBN10(digits) {
+			return "", false
+		}
+		body := "978" + digits[:9]
+		return body + isbn13CheckDigit(body), true
+	case 13:
+		if strings.Contains(digits, "X") || isbn13CheckDigit(digits[:12]) != digits[12:] {
+			return "", false
+		}
+		return digits, true
+	}
+	return "", false
+}
+
+func validISBN10(digits string) bool {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		var v int
+		switch {
+		case digits[i] == 'X' && i == 9:
+			v = 10
+		case digits[i] >= '0' && digits[i] <= '9':
+			v = int(digits[i] - '0')
+		default:
+			return false
+		}
+		sum += v * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+func isbn13CheckDigit(body string) string {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(body[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return string(rune('0' + (10-sum%10)%10))
+}
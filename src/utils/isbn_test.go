@@ -0,0 +1,37 @@
+package utils
+
+import "testing"
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		name  string
+		isbn  string
+		want  string
+		valid bool
+	}{
+		{"isbn13", "9780306406157", "9780306406157", true},
+		{"isbn13 with hyphens", "978-0-306-40615-7", "9780306406157", true},
+		{"isbn13 with spaces", "978 0 306 40615 7", "9780306406157", true},
+		{"isbn10 converted", "0-306-40615-2", "9780306406157", true},
+		{"isbn10 with X check digit", "0-8044-2957-X", "9780804429573", true},
+		{"isbn10 with lowercase x", "080442957x", "9780804429573", true},
+		{"isbn13 wrong check digit", "9780306406158", "", false},
+		{"isbn10 wrong check digit", "0306406153", "", false},
+		{"isbn10 with X before the end", "X306406152", "", false},
+		{"isbn13 with X", "978030640615X", "", false},
+		{"letters", "97803064061a7", "", false},
+		{"other separators", "978.0.306.40615.7", "", false},
+		{"too short", "978030640615", "", false},
+		{"too long", "97803064061570", "", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeISBN(tt.isbn)
+			if got != tt.want || ok != tt.valid {
+				t.Errorf("NormalizeISBN(%q) = %q, %v; want %q, %v", tt.isbn, got, ok, tt.want, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[string, int](2, time.Minute)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Get("a") // b is now the least recently used.
+	c.Add("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("b was not evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if got, ok := c.Get(key); !ok || got != want {
+			t.Errorf("Get(%q) = %d, %v; want %d, true", key, got, ok, want)
+		}
+	}
+}
+
+func TestLRUAddReplaces(t *testing.T) {
+	c := NewLRU[string, int](2, time.Minute)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("a", 10) // Replacing a also makes it the most recently used.
+	c.Add("c", 3)
+
+	if got, ok := c.Get("a"); !ok || got != 10 {
+		t.Errorf("Get(a) = %d, %v; want 10, true", got, ok)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("b was not evicted")
+	}
+}
+
+func TestLRUExpires(t *testing.T) {
+	c := NewLRU[string, int](2, 10*time.Millisecond)
+	c.Add("a", 1)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("expired entry was returned")
+	}
+}
+
+func TestLRUPurge(t *testing.T) {
+	c := NewLRU[string, int](2, time.Minute)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Purge()
+
+	for _, key := range []string{"a", "b"} {
+		if _, ok := c.Get(key); ok {
+			t.Errorf("%q survived Purge", key)
+		}
+	}
+	c.Add("c", 3)
+	if got, ok := c.Get("c"); !ok || got != 3 {
+		t.Errorf("Get(c) after Purge = %d, %v; want 3, true", got, ok)
+	}
+}
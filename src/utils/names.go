@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizePersonName reduces a name to a comparison key: lowercase, punctuation
+// removed and runs of initials joined, so "J.K. Rowling", "J. K. Rowling" and
+// "JK Rowling" all become "jk rowling".
+func NormalizePersonName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	out := make([]string, 0, len(fields))
+	initials := ""
+	for _, f := range fields {
+		if len([]rune(f)) == 1 {
+			initials += f
+			continue
+		}
+		if initials != "" {
+			out = append(out, initials)
+			initials = ""
+		}
+		out = append(out, f)
+	}
+	if initials != "" {
+		out = append(out, initials)
+	}
+	return strings.Join(out, " ")
+}
+
+// Slugify turns a display name into a lowercase, hyphen-separated key such as
+// "staff-picks", used for list slugs and curated tags.
+func Slugify(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, "-")
+}
@@ -0,0 +1,14 @@
+package utils
+
+import "github.com/gofiber/fiber/v2"
+
+// RouteTemplate returns the path template of the route that handled the request,
+// such as /books/:id, or "unmatched" when no route did. Call it after c.Next().
+func RouteTemplate(c *fiber.Ctx) string {
+	// A request no route matched is left on the middleware's own "/" route.
+	route := c.Route().Path
+	if route == "/" && c.Path() != "/" {
+		return "unmatched"
+	}
+	return route
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTextSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"dune", "dune", 1},
+		{"dune", "", 0},
+		{"abc", "xyz", 0},
+		{"kitten", "sitting", 1 - 3.0/7},
+		{"dune", "dunes", 1 - 1.0/5},
+		{"años", "anos", 1 - 1.0/4},
+	}
+
+	for _, tt := range tests {
+		got := TextSimilarity(tt.a, tt.b)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("TextSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if back := TextSimilarity(tt.b, tt.a); math.Abs(back-got) > 1e-9 {
+			t.Errorf("TextSimilarity(%q, %q) = %v, not symmetric with %v", tt.b, tt.a, back, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/text/language"
+)
+
+var (
+	validatorOnce sync.Once
+	validate      *validator.Validate
+)
+
+// Validator returns the app's shared validator. It caches struct metadata, so it is
+// built once, with the custom tags registered and fields named after their JSON
+// keys:
+//
+//	isbn        an ISBN-10 or ISBN-13 with a valid check digit; hyphens and spaces allowed
+//	year_range  a year no later than next year and no earlier than the param, 1 by default
+//	objectid    a hex MongoDB ObjectID
+//	bcp47       a well-formed BCP 47 language tag such as "en" or "pt-BR"
+func Validator() *validator.Validate {
+	validatorOnce.Do(func() {
+		validate = validator.New()
+		validate.RegisterTagNameFunc(fieldName)
+		validate.RegisterValidation("isbn", validateISBN)
+		validate.RegisterValidation("year_range", validateYearRange)
+		validate.RegisterValidation("objectid", validateObjectID)
+		validate.RegisterValidation("bcp47", validateBCP47)
+	})
+	return validate
+}
+
+// ValidateStruct validates s against its validate tags with the shared validator.
+func ValidateStruct(s interface{}) error {
+	return Validator().Struct(s)
+}
+
+// Validatable is implemented by DTOs that check more than their struct tags.
+type Validatable interface {
+	Validate() error
+}
+
+// ParseBody decodes the request body into out and validates it, reporting either
+// failure as a 400 the ErrorHandler can render.
+func ParseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		return BadRequest("invalid_body", err.Error())
+	}
+	return validateParsed(out)
+}
+
+// ParseQuery decodes the query string into out and validates it like ParseBody.
+func ParseQuery(c *fiber.Ctx, out interface{}) error {
+	if err := c.QueryParser(out); err != nil {
+		return BadRequest("invalid_query", err.Error())
+	}
+	return validateParsed(out)
+}
+
+func validateParsed(out interface{}) error {
+	var err error
+	if v, ok := out.(Validatable); ok {
+		err = v.Validate()
+	} else {
+		err = ValidateStruct(out)
+	}
+	if err != nil {
+		return ValidationFailed(err)
+	}
+	return nil
+}
+
+// fieldName names a field after its json, query or form key, in that order, so
+// errors use the names clients send. Fields without any keep their Go name.
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "query", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func validateISBN(fl validator.FieldLevel) bool {
+	_, ok := NormalizeISBN(fl.Field().String())
+	return ok
+}
+
+// validateYearRange allows next year so announced titles can be entered.
+func validateYearRange(fl validator.FieldLevel) bool {
+	lowest := int64(1)
+	if param := fl.Param(); param != "" {
+		parsed, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			panic("year_range: invalid lower bound " + param)
+		}
+		lowest = parsed
+	}
+	year := fl.Field().Int()
+	return year >= lowest && year <= int64(time.Now().Year()+1)
+}
+
+func validateObjectID(fl validator.FieldLevel) bool {
+	_, err := primitive.ObjectIDFromHex(fl.Field().String())
+	return err == nil
+}
+
+func validateBCP47(fl validator.FieldLevel) bool {
+	_, err := language.Parse(fl.Field().String())
+	return err == nil
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// XLSXWriter writes a single-sheet workbook row by row. Cells are stored as inline
+// strings so no shared-string table has to be built in memory, which keeps the
+// writer usable for exports of any size.
+type XLSXWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxWorkbookHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`
+	xlsxWorkbookTail = `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxSheetHead    = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetTail = `</sheetData></worksheet>`
+)
+
+// NewXLSXWriter writes the workbook scaffolding to w and opens the sheet for rows.
+func NewXLSXWriter(w io.Writer, sheetName string) (*XLSXWriter, error) {
+	zw := zip.NewWriter(w)
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/workbook.xml", xlsxWorkbookHead + xmlEscape(sheetName) + xlsxWorkbookTail},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, xlsxSheetHead); err != nil {
+		return nil, err
+	}
+
+	return &XLSXWriter{zw: zw, sheet: sheet}, nil
+}
+
+// WriteRow appends one row of string cells to the sheet.
+func (x *XLSXWriter) WriteRow(cells []string) error {
+	var b strings.Builder
+	b.WriteString(`<row>`)
+	for _, cell := range cells {
+		b.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		b.WriteString(xmlEscape(cell))
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+
+	_, err := io.WriteString(x.sheet, b.String())
+	return err
+}
+
+// Close finishes the sheet and the zip archive. It does not close the underlying writer.
+func (x *XLSXWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, xlsxSheetTail); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	// EscapeText also replaces characters that are not allowed in XML 1.0.
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}